@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ActivityInput sets the time window considered by the transferActivity flow.
+type ActivityInput struct {
+	// WindowDays is how far back items still count toward activity (default defaultActivityWindowDays).
+	WindowDays int `json:"windowDays,omitempty"`
+}
+
+// ActivityOutput is a dashboard-friendly gauge of transfer market activity.
+type ActivityOutput struct {
+	ActivityScore int    `json:"activityScore"`
+	Label         string `json:"label"`
+	TransferItems int    `json:"transferItems"`
+	FeedsOK       int    `json:"feedsOk"`
+	// ContextAsOf is the date of the newest item considered, or the fetch time when no item date is known.
+	ContextAsOf time.Time `json:"contextAsOf"`
+}
+
+const (
+	defaultActivityWindowDays = 7
+	// activitySaturation is the weighted item count mapped to a score of 100.
+	activitySaturation = 10.0
+	// undatedItemWeight is the weight of an item whose date cannot be parsed.
+	undatedItemWeight = 0.5
+)
+
+// defineActivityFlow registers transferActivity, which scores market activity in Go without calling the model.
+func defineActivityFlow(g *genkit.Genkit) *core.Flow[ActivityInput, ActivityOutput, struct{}] {
+	return genkit.DefineFlow(g, "transferActivity",
+		func(ctx context.Context, in ActivityInput) (ActivityOutput, error) {
+			window := time.Duration(in.WindowDays) * 24 * time.Hour
+			if window <= 0 {
+				window = defaultActivityWindowDays * 24 * time.Hour
+			}
+
+			items, _, stats := gatherFeedItems(ctx, contextOptions{})
+			var transfers []rssItem
+			for _, it := range items {
+				if isTransferItem(it) {
+					transfers = append(transfers, it)
+				}
+			}
+
+			score := activityScore(transfers, time.Now(), window)
+			return ActivityOutput{
+				ActivityScore: score,
+				Label:         activityLabel(score),
+				TransferItems: len(transfers),
+				FeedsOK:       stats.FeedsOK,
+				ContextAsOf:   stats.ContextAsOf,
+			}, nil
+		},
+	)
+}
+
+// activityScore weights each item by its recency (1 when just published, 0 at the end of window)
+// and maps the total onto 0–100, saturating at activitySaturation.
+func activityScore(items []rssItem, now time.Time, window time.Duration) int {
+	var total float64
+	for _, it := range items {
+		t, ok := parsePubDate(it.PubDate)
+		if !ok {
+			total += undatedItemWeight
+			continue
+		}
+		age := now.Sub(t)
+		if age < 0 {
+			age = 0
+		}
+		if age < window {
+			total += 1 - float64(age)/float64(window)
+		}
+	}
+	return int(math.Round(math.Min(total/activitySaturation, 1) * 100))
+}
+
+func activityLabel(score int) string {
+	switch {
+	case score < 20:
+		return "calme"
+	case score < 50:
+		return "modéré"
+	case score < 80:
+		return "animé"
+	default:
+		return "très animé"
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTransferActivityUsesFeedLanguageKeywords(t *testing.T) {
+	serveFeed(t, `<rss><channel>
+<item><title>Evenepoel joins Red Bull</title></item>
+<item><title>Race results</title></item>
+</channel></rss>`)
+	cyclingFeeds[0].lang = "en"
+
+	out, err := defineActivityFlow(newTestGenkit(t)).Run(context.Background(), ActivityInput{})
+	if err != nil {
+		t.Fatalf("transferActivity: %v", err)
+	}
+	if out.TransferItems != 1 {
+		t.Errorf("TransferItems = %d, want the English signing counted", out.TransferItems)
+	}
+}
+
+func TestIsTransferItem(t *testing.T) {
+	tests := []struct {
+		it   rssItem
+		want bool
+	}{
+		{rssItem{Title: "Evenepoel joins Red Bull", Lang: "en"}, true},
+		{rssItem{Title: "Evenepoel joins Red Bull", Lang: "fr"}, false},
+		{rssItem{Title: "Pogačar rejoint Cofidis", Lang: "fr"}, true},
+		{rssItem{Title: "Pogačar rejoint Cofidis"}, true},
+	}
+	for _, tt := range tests {
+		if got := isTransferItem(tt.it); got != tt.want {
+			t.Errorf("isTransferItem(%q, lang %q) = %v, want %v", tt.it.Title, tt.it.Lang, got, tt.want)
+		}
+	}
+}
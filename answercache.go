@@ -0,0 +1,79 @@
+package main
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// answerCache is a size-bounded LRU of qaFlow answers, keyed by normalized question, whose entries
+// expire after ttl. A size of zero disables it. It is safe for concurrent use.
+type answerCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // front is the most recently used; values are *answerCacheEntry
+	entries map[string]*list.Element
+}
+
+type answerCacheEntry struct {
+	key     string
+	out     AnswerOutput
+	expires time.Time
+}
+
+func newAnswerCache(size int, ttl time.Duration) *answerCache {
+	return &answerCache{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// qaAnswers caches qaFlow answers (QA_CACHE_SIZE, QA_CACHE_TTL).
+var qaAnswers = newAnswerCache(0, 10*time.Minute)
+
+// answerCacheKey normalizes question so that answers are shared across case and spacing variants.
+func answerCacheKey(question string) string {
+	return collapseWhitespace(strings.ToLower(question))
+}
+
+// get returns the unexpired answer cached for key and marks it as recently used.
+func (c *answerCache) get(key string) (AnswerOutput, bool) {
+	if c.size <= 0 {
+		return AnswerOutput{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return AnswerOutput{}, false
+	}
+	e := el.Value.(*answerCacheEntry)
+	if !time.Now().Before(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return AnswerOutput{}, false
+	}
+	c.order.MoveToFront(el)
+	return e.out, true
+}
+
+// put stores out under key, evicting the least recently used entry when the cache is full.
+func (c *answerCache) put(key string, out AnswerOutput) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &answerCacheEntry{key: key, out: out, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*answerCacheEntry).key)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAnswerCache(t *testing.T) {
+	c := newAnswerCache(2, time.Minute)
+	if _, ok := c.get(answerCacheKey("Qui ?")); ok {
+		t.Fatal("hit on an empty cache")
+	}
+
+	c.put(answerCacheKey("Qui a gagné le Tour ?"), AnswerOutput{Answer: "Pogačar"})
+	got, ok := c.get(answerCacheKey("  qui A gagné   le tour ? "))
+	if !ok || got.Answer != "Pogačar" {
+		t.Errorf("case and spacing variant: got %+v, %v; want the cached answer", got, ok)
+	}
+	if _, ok := c.get(answerCacheKey("Qui a gagné le Giro ?")); ok {
+		t.Error("hit for a different question")
+	}
+
+	// "Qui a gagné le Tour ?" was just used, so "b" is the least recently used when "c" comes in.
+	c.put("b", AnswerOutput{Answer: "b"})
+	c.get(answerCacheKey("Qui a gagné le Tour ?"))
+	c.put("c", AnswerOutput{Answer: "c"})
+	if _, ok := c.get("b"); ok {
+		t.Error("the least recently used entry was not evicted")
+	}
+	for _, key := range []string{answerCacheKey("Qui a gagné le Tour ?"), "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("%q was evicted", key)
+		}
+	}
+}
+
+func TestAnswerCacheExpiryAndDisabled(t *testing.T) {
+	c := newAnswerCache(2, time.Millisecond)
+	c.put("k", AnswerOutput{Answer: "a"})
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.get("k"); ok {
+		t.Error("hit on an expired entry")
+	}
+
+	off := newAnswerCache(0, time.Minute)
+	off.put("k", AnswerOutput{Answer: "a"})
+	if _, ok := off.get("k"); ok {
+		t.Error("hit on a disabled cache")
+	}
+}
+
+func TestQAFlowServesCachedAnswers(t *testing.T) {
+	saved := qaAnswers
+	qaAnswers = newAnswerCache(4, time.Minute)
+	t.Cleanup(func() { qaAnswers = saved })
+	gen := &stubGenerator{reply: replyText("Pogačar.", "stub")}
+	flow := defineQAFlow(newTestGenkit(t), gen)
+
+	first, err := flow.Run(context.Background(), QuestionInput{Question: "Qui a gagné le Tour ?"})
+	if err != nil {
+		t.Fatalf("qaFlow: %v", err)
+	}
+	second, err := flow.Run(context.Background(), QuestionInput{Question: "QUI a gagné  le Tour ?"})
+	if err != nil {
+		t.Fatalf("qaFlow: %v", err)
+	}
+	if first.Cached || !second.Cached || second.Answer != first.Answer {
+		t.Errorf("first %+v, second %+v; want the second served from the cache", first, second)
+	}
+	if n := len(gen.calls()); n != 1 {
+		t.Errorf("the model was called %d times, want 1", n)
+	}
+}
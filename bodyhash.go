@@ -0,0 +1,45 @@
+package main
+
+import (
+	"crypto/sha256"
+	"slices"
+	"sync"
+)
+
+// bodyHashEntry is the SHA-256 of the last body downloaded for a feed URL, with the items it
+// produced under limit.
+type bodyHashEntry struct {
+	sum   [sha256.Size]byte
+	limit int
+	items []rssItem
+}
+
+// bodyHashStore lets fetchRSSItems reuse the parse of a body identical to the previous one, for
+// servers that send no validators or ignore them; it is safe for concurrent use.
+type bodyHashStore struct {
+	mu      sync.Mutex
+	entries map[string]bodyHashEntry
+}
+
+// bodyHashes is the bodyHashStore used by fetchRSSItems.
+var bodyHashes = &bodyHashStore{entries: make(map[string]bodyHashEntry)}
+
+// lookup returns a copy of the items parsed for feedURL when body and limit match the last fetch.
+func (s *bodyHashStore) lookup(feedURL string, body []byte, limit int) ([]rssItem, bool) {
+	sum := sha256.Sum256(body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[feedURL]
+	if !ok || e.sum != sum || e.limit != limit {
+		return nil, false
+	}
+	return slices.Clone(e.items), true
+}
+
+// put remembers the hash of body and the items parsed from it.
+func (s *bodyHashStore) put(feedURL string, body []byte, limit int, items []rssItem) {
+	e := bodyHashEntry{sum: sha256.Sum256(body), limit: limit, items: slices.Clone(items)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[feedURL] = e
+}
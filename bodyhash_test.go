@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUnchangedBodySkipsParsing(t *testing.T) {
+	var body atomic.Value
+	body.Store(testFeed)
+	srv, hits := countingFeedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	})
+	feedURL := srv.URL + "/rss"
+	ctx := context.Background()
+
+	if _, err := downloadFeedItems(ctx, feedURL, nil, 10); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	// Mark the stored parse: only a fetch that skips parsing can return it.
+	bodyHashes.mu.Lock()
+	e := bodyHashes.entries[feedURL]
+	e.items = []rssItem{{Title: "parse réutilisée"}}
+	bodyHashes.entries[feedURL] = e
+	bodyHashes.mu.Unlock()
+
+	items, err := downloadFeedItems(ctx, feedURL, nil, 10)
+	if err != nil || len(items) != 1 || items[0].Title != "parse réutilisée" {
+		t.Errorf("identical body: got %+v, %v; want the stored parse", items, err)
+	}
+	if items, _ := downloadFeedItems(ctx, feedURL, nil, 1); len(items) != 1 || items[0].Title == "parse réutilisée" {
+		t.Errorf("identical body with another limit: got %+v, want a fresh parse", items)
+	}
+
+	body.Store(`<rss><channel><item><title>Mercato : Pidcock quitte Ineos</title></item></channel></rss>`)
+	items, err = downloadFeedItems(ctx, feedURL, nil, 10)
+	if err != nil || len(items) != 1 || items[0].Title != "Mercato : Pidcock quitte Ineos" {
+		t.Errorf("changed body: got %+v, %v; want the new item", items, err)
+	}
+	if n := hits.Load(); n != 4 {
+		t.Errorf("%d requests, want 4: the hash only saves parsing, not the download", n)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops fetching a feed URL after threshold consecutive failures. The URL then stays
+// open for cooldown, during which fetchFirstWorkingFeed skips it; afterwards, a single half-open
+// attempt decides whether it closes again or stays open for another cooldown. A zero threshold
+// disables the breaker. It also keeps the outcome of the last fetch of each URL, for /feeds.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*breakerState
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time // zero while closed
+	probing   bool      // a half-open attempt is in flight
+
+	lastFetch time.Time
+	lastItems int
+	lastErr   string
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, states: make(map[string]*breakerState)}
+}
+
+// feedBreaker guards every feed URL (FEED_BREAKER_THRESHOLD, FEED_BREAKER_COOLDOWN).
+var feedBreaker = newCircuitBreaker(3, 5*time.Minute)
+
+// allow reports whether feedURL may be fetched now. Once the cooldown is over it lets exactly one
+// caller through as the half-open attempt, whose outcome must be passed to record.
+func (b *circuitBreaker) allow(feedURL string) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.states[feedURL]
+	if !ok || s.openUntil.IsZero() {
+		return true
+	}
+	if time.Now().Before(s.openUntil) || s.probing {
+		return false
+	}
+	s.probing = true
+	return true
+}
+
+// record updates the state of feedURL after a download that returned items: a success closes it,
+// a failure counts towards threshold and reopens it when a half-open attempt fails. Cancellations
+// say nothing about the feed and only release the half-open attempt.
+func (b *circuitBreaker) record(feedURL string, items int, err error) {
+	if errors.Is(err, context.Canceled) {
+		b.release(feedURL)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.states[feedURL]
+	if !ok {
+		s = &breakerState{}
+		b.states[feedURL] = s
+	}
+	s.lastFetch, s.lastItems, s.lastErr = time.Now(), items, ""
+	if err == nil {
+		s.failures, s.openUntil, s.probing = 0, time.Time{}, false
+		return
+	}
+	s.lastErr = err.Error()
+	s.failures++
+	if b.threshold > 0 && (s.probing || s.failures >= b.threshold) {
+		s.openUntil = time.Now().Add(b.cooldown)
+	}
+	s.probing = false
+}
+
+// release ends the half-open attempt allow let through for feedURL without recording an outcome,
+// for attempts that did not download the feed.
+func (b *circuitBreaker) release(feedURL string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.states[feedURL]; ok {
+		s.probing = false
+	}
+}
+
+// feedURLStatus is the state of one feed URL as reported by /feeds.
+type feedURLStatus struct {
+	URL       string     `json:"url"`
+	LastFetch *time.Time `json:"lastFetch,omitempty"`
+	LastItems int        `json:"lastItems"`
+	LastError string     `json:"lastError,omitempty"`
+	// Circuit is "closed", "open" or "half-open".
+	Circuit string `json:"circuit"`
+	// Fingerprint identifies the vocabulary of the last download (see fingerprintItems) and
+	// FingerprintChanged is when it last changed; both are empty until a download succeeds.
+	Fingerprint        string     `json:"fingerprint,omitempty"`
+	FingerprintChanged *time.Time `json:"fingerprintChanged,omitempty"`
+}
+
+// status returns the recorded state of feedURL; a URL never fetched is closed with no fetch time.
+func (b *circuitBreaker) status(feedURL string) feedURLStatus {
+	st := feedURLStatus{URL: redactURL(feedURL), Circuit: "closed"}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.states[feedURL]
+	if !ok {
+		return st
+	}
+	if !s.lastFetch.IsZero() {
+		at := s.lastFetch
+		st.LastFetch = &at
+	}
+	st.LastItems, st.LastError = s.lastItems, s.lastErr
+	switch {
+	case s.openUntil.IsZero():
+	case time.Now().Before(s.openUntil):
+		st.Circuit = "open"
+	default:
+		st.Circuit = "half-open"
+	}
+	return st
+}
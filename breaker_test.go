@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreakerCanceledHalfOpenAttemptIsReleased(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond)
+	b.record("u", 0, errors.New("boom"))
+	time.Sleep(2 * time.Millisecond)
+
+	if !b.allow("u") {
+		t.Fatal("the half-open attempt was refused")
+	}
+	b.record("u", 0, fmt.Errorf("fetching: %w", context.Canceled))
+	if !b.allow("u") {
+		t.Error("a canceled half-open attempt kept the circuit probing")
+	}
+	if got := b.status("u").LastError; got != "boom" {
+		t.Errorf("LastError = %q, want the cancellation ignored", got)
+	}
+}
+
+func TestFetchFirstWorkingFeedCacheHitKeepsLastFetch(t *testing.T) {
+	srv := serveFeed(t, testFeed)
+	feedURL := srv.URL + "/cached"
+	ctx := context.Background()
+
+	if _, _, _, _, err := fetchFirstWorkingFeed(ctx, []string{feedURL}, nil, 5, true); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	first := feedBreaker.status(feedURL).LastFetch
+	if first == nil {
+		t.Fatal("the download was not recorded")
+	}
+	if _, _, _, _, err := fetchFirstWorkingFeed(ctx, []string{feedURL}, nil, 5, false); err != nil {
+		t.Fatalf("cached fetch: %v", err)
+	}
+	if got := feedBreaker.status(feedURL).LastFetch; !got.Equal(*first) {
+		t.Errorf("LastFetch moved from %v to %v on a cache hit", *first, *got)
+	}
+}
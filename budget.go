@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// maxContextChars caps the characters of feed snippets sent to the model (MAX_CONTEXT_CHARS); 0 means no cap.
+var maxContextChars = 6000
+
+// fitContextBudget keeps the most recent items whose snippets, one per line, fit in max characters,
+// and reports how many older items were left out. The kept items stay in their original order, and
+// the newest one is always kept so the model has some context even if it alone exceeds max.
+func fitContextBudget(items []rssItem, max int) ([]rssItem, int) {
+	if max <= 0 || len(items) == 0 {
+		return items, 0
+	}
+
+	keep := make([]bool, len(items))
+	used := 0
+	for n, i := range newestFirst(items) {
+		size := snippetSize(items[i])
+		if n > 0 && used+size > max {
+			break
+		}
+		keep[i] = true
+		used += size
+	}
+
+	var kept []rssItem
+	for i, it := range items {
+		if keep[i] {
+			kept = append(kept, it)
+		}
+	}
+	return kept, len(items) - len(kept)
+}
+
+// dropOverflowingExcerpts clears, newest item first, the excerpts that would take the snippets of
+// items past max characters, so enriching the items kept by fitContextBudget respects the same cap.
+func dropOverflowingExcerpts(items []rssItem, max int) []rssItem {
+	if max <= 0 {
+		return items
+	}
+	out := make([]rssItem, len(items))
+	copy(out, items)
+	used := 0
+	for _, it := range out {
+		it.Excerpt = ""
+		used += snippetSize(it)
+	}
+	for _, i := range newestFirst(out) {
+		if out[i].Excerpt == "" {
+			continue
+		}
+		bare := out[i]
+		bare.Excerpt = ""
+		extra := snippetSize(out[i]) - snippetSize(bare)
+		if used+extra > max {
+			out[i].Excerpt = ""
+			continue
+		}
+		used += extra
+	}
+	return out
+}
+
+// newestFirst returns the indexes of items from the newest to the oldest, ties in original order.
+func newestFirst(items []rssItem) []int {
+	byDate := make([]int, len(items))
+	for i := range byDate {
+		byDate[i] = i
+	}
+	sort.SliceStable(byDate, func(a, b int) bool { return newerItem(items[byDate[a]], items[byDate[b]]) })
+	return byDate
+}
+
+// snippetSize is the number of characters the context snippet of it takes, trailing newline included.
+func snippetSize(it rssItem) int {
+	return utf8.RuneCountInString(contextSnippet(it)) + 1
+}
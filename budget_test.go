@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFitContextBudgetKeepsTheNewestItems(t *testing.T) {
+	base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	var items []rssItem
+	// Days 0..9 in a shuffled order, so the cut depends on dates rather than on positions.
+	for _, day := range []int{3, 9, 0, 7, 5, 1, 8, 2, 6, 4} {
+		items = append(items, rssItem{
+			Title:   fmt.Sprintf("Transfert %d : %s", day, strings.Repeat("x", 200)),
+			PubDate: base.AddDate(0, 0, day).Format(time.RFC1123Z),
+		})
+	}
+	size := snippetSize(items[0])
+	max := 3*size + size/2
+
+	kept, omitted := fitContextBudget(items, max)
+	var days []string
+	used := 0
+	for _, it := range kept {
+		days = append(days, strings.Fields(it.Title)[1])
+		used += snippetSize(it)
+	}
+	if want := []string{"9", "7", "8"}; !slices.Equal(days, want) || omitted != 7 {
+		t.Errorf("kept days %v with %d omitted, want the three newest %v in feed order and 7 omitted", days, omitted, want)
+	}
+	if used > max {
+		t.Errorf("kept snippets take %d characters, over the %d budget", used, max)
+	}
+
+	if kept, omitted := fitContextBudget(items, 10); len(kept) != 1 || !strings.HasPrefix(kept[0].Title, "Transfert 9 ") || omitted != 9 {
+		t.Errorf("tiny budget: kept %d items (%d omitted), want only the newest", len(kept), omitted)
+	}
+	if kept, omitted := fitContextBudget(items, 0); len(kept) != len(items) || omitted != 0 {
+		t.Errorf("no budget: kept %d items (%d omitted), want all", len(kept), omitted)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ClaimInput is a free-text transfer claim to check, e.g. "X signe chez Y".
+type ClaimInput struct {
+	Claim string `json:"claim"`
+}
+
+// ClaimOutput is the verdict on a claim along with the articles it is based on.
+type ClaimOutput struct {
+	// Verdict is one of verdictOfficial, verdictRumored or verdictUnsupported.
+	Verdict string `json:"verdict"`
+	// Confidence is the model's confidence in Verdict (0–1); 0 when no evidence was found.
+	Confidence  float64  `json:"confidence"`
+	Explanation string   `json:"explanation,omitempty"`
+	Sources     []string `json:"sources"`
+}
+
+const (
+	verdictOfficial    = "official"
+	verdictRumored     = "rumored"
+	verdictUnsupported = "unsupported"
+
+	// maxClaimEvidence caps how many matching articles are shown to the model.
+	maxClaimEvidence = 5
+	// minClaimTokenLen ignores short words ("de", "chez") when matching a claim against titles.
+	minClaimTokenLen = 4
+)
+
+// claimVerdict is the structured answer requested from the model.
+type claimVerdict struct {
+	Verdict     string  `json:"verdict"`
+	Confidence  float64 `json:"confidence"`
+	Explanation string  `json:"explanation"`
+}
+
+// defineClaimFlow registers verifyTransferClaim, which classifies a claim as official, rumored or unsupported.
+func defineClaimFlow(g *genkit.Genkit, gen Generator) *core.Flow[ClaimInput, ClaimOutput, struct{}] {
+	return genkit.DefineFlow(g, "verifyTransferClaim",
+		func(ctx context.Context, in ClaimInput) (out ClaimOutput, err error) {
+			claim := strings.TrimSpace(in.Claim)
+			start := time.Now()
+			var stats feedStats
+			var res GenerateResult
+			defer func() {
+				logFlowSummary(flowSummary{
+					Flow: "verifyTransferClaim", Question: claim,
+					FeedsOK: stats.FeedsOK, FeedsTotal: stats.FeedsTotal, Items: stats.Items,
+					Model: respondingModel(res.Model), CacheHit: stats.FeedsOK > 0 && stats.FeedsCached == stats.FeedsOK,
+					Duration: time.Since(start), Err: err,
+				})
+			}()
+			if claim == "" {
+				return ClaimOutput{}, errors.New("claim must not be empty")
+			}
+
+			ctx, cancel := withFlowDeadline(ctx)
+			defer cancel()
+
+			var items []rssItem
+			items, _, stats = gatherFeedItems(ctx, contextOptions{})
+			if err := ctx.Err(); err != nil {
+				return ClaimOutput{}, deadlineError(ctx, "fetching feeds", err)
+			}
+			evidence := claimEvidence(claim, items)
+			if len(evidence) == 0 {
+				return ClaimOutput{
+					Verdict:     verdictUnsupported,
+					Explanation: "Aucun article récent ne mentionne cette mutation.",
+					Sources:     []string{},
+				}, nil
+			}
+
+			var lines, sources []string
+			for _, it := range evidence {
+				lines = append(lines, fmt.Sprintf("- %s (%s)", it.Title, it.PubDate))
+				if link, ok := sanitizeSourceURL(it.Link); ok {
+					sources = append(sources, link)
+				}
+			}
+			prompt := fmt.Sprintf(
+				"Tu es un assistant cyclisme qui vérifie des informations de transfert.\n"+
+					"Articles :\n%s\n\n"+
+					"Affirmation : %s\n"+
+					"À partir de ces articles uniquement, indique si l'affirmation est officielle (%q : annonce de l'équipe ou du coureur), "+
+					"une rumeur (%q) ou non étayée (%q), avec une confiance entre 0 et 1 et une courte explication en français.",
+				strings.Join(lines, "\n"), claim, verdictOfficial, verdictRumored, verdictUnsupported,
+			)
+
+			var v *claimVerdict
+			v, res, err = generateData[claimVerdict](ctx, gen, GenerateRequest{Model: modelName, Prompt: prompt})
+			if errors.Is(err, errMalformedOutput) {
+				// Without a readable verdict the claim is not corroborated; the raw answer explains why.
+				slog.Warn("sortie JSON du modèle invalide, affirmation non étayée", "err", err)
+				v, err = &claimVerdict{Verdict: verdictUnsupported, Explanation: strings.TrimSpace(res.Text)}, nil
+			}
+			if err != nil {
+				return ClaimOutput{}, modelError(deadlineError(ctx, "generating the verdict", err))
+			}
+
+			out = ClaimOutput{Verdict: v.Verdict, Confidence: v.Confidence, Explanation: v.Explanation, Sources: sources}
+			switch out.Verdict {
+			case verdictOfficial, verdictRumored, verdictUnsupported:
+			default:
+				out.Verdict = verdictUnsupported
+			}
+			out.Confidence = min(max(out.Confidence, 0), 1)
+			return out, nil
+		},
+	)
+}
+
+// claimEvidence returns the items whose titles share the most significant words with claim, best first,
+// ignoring case and accents. Transfer keywords are ignored so that "signe" alone does not match every signing.
+func claimEvidence(claim string, items []rssItem) []rssItem {
+	var tokens []string
+	lang := detectQuestionLanguage(claim)
+	for _, w := range strings.FieldsFunc(foldAccents(claim), isClaimSeparator) {
+		if len([]rune(w)) >= minClaimTokenLen && !hasTransferKeyword(w, lang) {
+			tokens = append(tokens, w)
+		}
+	}
+
+	type scored struct {
+		item  rssItem
+		score int
+	}
+	var matches []scored
+	for _, it := range items {
+		title := foldAccents(it.Title)
+		score := 0
+		for _, tok := range tokens {
+			if strings.Contains(title, tok) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{it, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	var evidence []rssItem
+	for i, m := range matches {
+		if i == maxClaimEvidence {
+			break
+		}
+		evidence = append(evidence, m.item)
+	}
+	return evidence
+}
+
+func isClaimSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+}
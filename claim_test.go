@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClaimEvidenceIgnoresAccents(t *testing.T) {
+	items := []rssItem{
+		{Title: "Résultats du Dauphiné"},
+		{Title: "Pogačar rejoint l'équipe Arkéa"},
+	}
+	got := claimEvidence("POGACAR signe chez Arkea", items)
+	if len(got) != 1 || got[0].Title != items[1].Title {
+		t.Errorf("claimEvidence = %+v, want the Pogačar article", got)
+	}
+}
+
+func TestVerifyTransferClaimSanitizesSources(t *testing.T) {
+	serveFeed(t, testFeed)
+	gen := &stubGenerator{reply: replyText(`{"verdict":"rumored","confidence":0.6,"explanation":"Un seul article."}`, "stub")}
+	flow := defineClaimFlow(newTestGenkit(t), gen)
+
+	out, err := flow.Run(context.Background(), ClaimInput{Claim: "Pogacar signe chez Cofidis"})
+	if err != nil {
+		t.Fatalf("verifyTransferClaim: %v", err)
+	}
+	if out.Verdict != verdictRumored {
+		t.Errorf("Verdict = %q, want %q", out.Verdict, verdictRumored)
+	}
+	if want := []string{"https://example.com/a"}; !reflect.DeepEqual(out.Sources, want) {
+		t.Errorf("Sources = %v, want %v without tracking parameters", out.Sources, want)
+	}
+}
+
+func TestVerifyTransferClaimVerdicts(t *testing.T) {
+	serveFeed(t, testFeed)
+	tests := []struct {
+		name, claim, reply string
+		want               string
+		wantCalls          int
+	}{
+		{"confirmed", "Pogacar signe chez Cofidis", `{"verdict":"official","confidence":0.9,"explanation":"Annonce de l'équipe."}`, verdictOfficial, 1},
+		{"rumored", "Pogacar signe chez Cofidis", `{"verdict":"rumored","confidence":0.5,"explanation":"Un seul article."}`, verdictRumored, 1},
+		{"denied", "Pogacar signe chez Cofidis", `{"verdict":"unsupported","confidence":0.8,"explanation":"L'article parle d'une autre équipe."}`, verdictUnsupported, 1},
+		{"unknown verdict", "Pogacar signe chez Cofidis", `{"verdict":"peut-être","confidence":3}`, verdictUnsupported, 1},
+		{"no evidence", "Vingegaard signe chez Movistar", "", verdictUnsupported, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &stubGenerator{reply: replyText(tt.reply, "stub")}
+			out, err := defineClaimFlow(newTestGenkit(t), gen).Run(context.Background(), ClaimInput{Claim: tt.claim})
+			if err != nil {
+				t.Fatalf("verifyTransferClaim: %v", err)
+			}
+			if out.Verdict != tt.want || out.Confidence < 0 || out.Confidence > 1 {
+				t.Errorf("got verdict %q with confidence %g, want %q within [0, 1]", out.Verdict, out.Confidence, tt.want)
+			}
+			if n := len(gen.calls()); n != tt.wantCalls {
+				t.Errorf("the model was called %d times, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestVerifyTransferClaimMalformedVerdict(t *testing.T) {
+	serveFeed(t, testFeed)
+	g := newTestGenkit(t)
+	model, _ := defineTextModel(g, "claim", "Probablement une rumeur.")
+	savedModel := modelName
+	modelName = model
+	t.Cleanup(func() { modelName = savedModel })
+
+	out, err := defineClaimFlow(g, genkitGenerator{g: g}).Run(context.Background(), ClaimInput{Claim: "Pogacar signe chez Cofidis"})
+	if err != nil {
+		t.Fatalf("verifyTransferClaim: %v", err)
+	}
+	if out.Verdict != verdictUnsupported || out.Confidence != 0 || out.Explanation != "Probablement une rumeur." {
+		t.Errorf("got %+v, want an unsupported verdict explained by the raw answer", out)
+	}
+}
+
+func TestVerifyTransferClaimErrors(t *testing.T) {
+	serveFeed(t, testFeed)
+	summaries := captureLogs(t, "flowSummary")
+	gen := &stubGenerator{reply: func(GenerateRequest) (GenerateResult, error) { return GenerateResult{}, errors.New("quota") }}
+	flow := defineClaimFlow(newTestGenkit(t), gen)
+
+	_, err := flow.Run(context.Background(), ClaimInput{Claim: "Pogacar signe chez Cofidis"})
+	if !errors.Is(err, ErrModel) {
+		t.Errorf("model failure: got %v, want ErrModel", err)
+	}
+	if got := summaries(); len(got) != 1 || got[0]["flow"] != "verifyTransferClaim" || got[0]["status"] != "error" {
+		t.Errorf("summaries = %v, want one error summary for verifyTransferClaim", got)
+	}
+
+	saved := flowDeadline
+	flowDeadline = time.Nanosecond
+	t.Cleanup(func() { flowDeadline = saved })
+	_, err = flow.Run(context.Background(), ClaimInput{Claim: "Pogacar signe chez Cofidis"})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "FLOW_TIMEOUT") {
+		t.Errorf("expired deadline: got %v, want a FLOW_TIMEOUT error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// feedValidators are the HTTP cache validators last returned for a feed URL, with the items
+// parsed from that response so a 304 Not Modified can be answered without a body.
+type feedValidators struct {
+	etag         string
+	lastModified string
+	items        []rssItem
+}
+
+// validatorStore maps feed URLs to their validators; it is safe for concurrent use.
+type validatorStore struct {
+	mu      sync.Mutex
+	entries map[string]feedValidators
+}
+
+// conditionalStore is the validatorStore used by fetchRSSItems.
+var conditionalStore = &validatorStore{entries: make(map[string]feedValidators)}
+
+func (s *validatorStore) get(feedURL string) (feedValidators, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.entries[feedURL]
+	return v, ok
+}
+
+// put remembers the ETag and Last-Modified of header along with items. Responses carrying
+// neither validator clear any previous entry.
+func (s *validatorStore) put(feedURL string, header http.Header, items []rssItem) {
+	v := feedValidators{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified"), items: slices.Clone(items)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v.etag == "" && v.lastModified == "" {
+		delete(s.entries, feedURL)
+		return
+	}
+	s.entries[feedURL] = v
+}
+
+// setConditionalHeaders adds If-None-Match and If-Modified-Since to req from v.
+func setConditionalHeaders(req *http.Request, v feedValidators) {
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
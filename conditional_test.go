@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestConditionalFetchReusesItemsOn304(t *testing.T) {
+	const etag, lastModified = `"v1"`, "Mon, 02 Jun 2025 10:00:00 GMT"
+	var gotIfNoneMatch, gotIfModifiedSince string
+	srv, hits := countingFeedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotIfNoneMatch, gotIfModifiedSince = r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")
+		if gotIfNoneMatch == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(testFeed))
+	})
+	feedURL := srv.URL + "/rss"
+	ctx := context.Background()
+
+	first, err := downloadFeedItems(ctx, feedURL, nil, 10)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("first fetch: %d items, %v", len(first), err)
+	}
+	if gotIfNoneMatch != "" || gotIfModifiedSince != "" {
+		t.Errorf("the first request was conditional: If-None-Match %q, If-Modified-Since %q", gotIfNoneMatch, gotIfModifiedSince)
+	}
+
+	second, err := downloadFeedItems(ctx, feedURL, nil, 10)
+	if err != nil {
+		t.Fatalf("304 fetch: %v", err)
+	}
+	if gotIfNoneMatch != etag || gotIfModifiedSince != lastModified {
+		t.Errorf("validators sent: If-None-Match %q, If-Modified-Since %q; want %q and %q", gotIfNoneMatch, gotIfModifiedSince, etag, lastModified)
+	}
+	if !reflect.DeepEqual(second, first) || hits.Load() != 2 {
+		t.Errorf("after %d requests, got %+v; want the first fetch's items from the 304", hits.Load(), second)
+	}
+}
+
+func TestUnexpected304WithoutStoredItems(t *testing.T) {
+	srv, _ := countingFeedServer(t, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotModified) })
+	if _, err := downloadFeedItems(context.Background(), srv.URL+"/rss", nil, 10); err == nil {
+		t.Error("a 304 to an unconditional request was accepted")
+	}
+}
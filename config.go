@@ -0,0 +1,462 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/plugins/googlegenai"
+)
+
+// cliOptions holds the command-line flags.
+type cliOptions struct {
+	serve bool
+	port  int
+	model string
+	// keywords is an optional file replacing the built-in transferKeywords, used by default-language feeds.
+	keywords string
+	// dryRun prints the cyclingRAG prompt instead of running the flows.
+	dryRun bool
+	// maxItems is the number of items kept per feed.
+	maxItems int
+	// logFormat selects the log handler: text (default) or json.
+	logFormat string
+	// metrics enables the /metrics endpoint in -serve mode.
+	metrics bool
+	// enrich adds the first paragraph of each article to the cyclingRAG context.
+	enrich bool
+	// feeds is an optional YAML or JSON file replacing the built-in cyclingFeeds.
+	feeds string
+	// rerank orders feed items by embedding similarity to the question instead of keywords.
+	rerank bool
+	// noPrivate refuses feed hosts with loopback, private or link-local addresses.
+	noPrivate bool
+	// maxAge drops items published longer ago (0 keeps everything); keepUndated spares undated items.
+	maxAge      time.Duration
+	keepUndated bool
+	// lang is the language of the cyclingRAG answer, a key of cyclingPromptTemplates.
+	lang string
+	// genConfig carries -temperature and -max-tokens; nil when neither is set.
+	genConfig *googlegenai.GeminiConfig
+	// cacheDir receives the downloaded feeds and model answers; offline reads them back instead.
+	cacheDir string
+	offline  bool
+	// truncateQuestion cuts qaFlow questions longer than MAX_QUESTION_CHARS instead of rejecting them.
+	truncateQuestion bool
+	// teams is an optional file of team names annotated on the context items.
+	teams string
+	// verbose logs at Debug level, adding per-feed details.
+	verbose bool
+	// question is asked to qaFlow instead of the demo (-question or positional arguments); empty runs the demo.
+	question string
+}
+
+func parseFlags() (cliOptions, error) {
+	var o cliOptions
+	maxItems, err := envInt("GENKIT_MAX_ITEMS", defaultMaxItemsPerFeed)
+	if err != nil {
+		return o, err
+	}
+	flag.BoolVar(&o.serve, "serve", false, "serve the flows over HTTP instead of running the demo once")
+	flag.IntVar(&o.port, "port", 3400, "HTTP port used with -serve")
+	flag.StringVar(&o.model, "model", envOr("GENKIT_MODEL", defaultModelName), "model used by the flows (env GENKIT_MODEL)")
+	flag.StringVar(&o.keywords, "keywords", "", "JSON array or newline-separated file of transfer keywords for French feeds (default: built-in list); feeds in other languages keep their built-in keywords")
+	flag.BoolVar(&o.dryRun, "dry-run", false, "print the cyclingRAG prompt and sources for the demo question without calling the model")
+	flag.IntVar(&o.maxItems, "max-items", maxItems, "items kept per feed (env GENKIT_MAX_ITEMS)")
+	flag.StringVar(&o.logFormat, "log-format", logFormatText, "log output format: text or json")
+	flag.BoolVar(&o.metrics, "metrics", false, "collect metrics and expose them on GET /metrics in -serve mode")
+	flag.BoolVar(&o.enrich, "enrich", false, "add the first paragraph of each article page to the context (slower)")
+	flag.BoolVar(&o.rerank, "rerank", false, "re-rank feed items by embedding similarity to the question (extra embedder call)")
+	flag.BoolVar(&o.noPrivate, "no-private", false, "refuse feed hosts resolving to loopback, private or link-local addresses")
+	flag.Func("max-age", "drop items older than this age, e.g. 30d or 72h (default: no limit)", func(s string) error {
+		age, err := parseAge(s)
+		if err == nil && age < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		o.maxAge = age
+		return err
+	})
+	flag.BoolVar(&o.keepUndated, "keep-undated", true, "keep items whose date cannot be parsed when -max-age is set")
+	flag.StringVar(&o.lang, "lang", "", "language of the cyclingRAG answer and messages: fr or en (default: fr answers, messages in the question's language)")
+	temperature := flag.Float64("temperature", 0, "model temperature, 0 to 2 (default: model default)")
+	maxTokens := flag.Int("max-tokens", 0, "maximum output tokens per model call (default: model default)")
+	flag.StringVar(&o.cacheDir, "cache-dir", "", "directory where fetched feeds and model answers are saved as JSON")
+	flag.BoolVar(&o.offline, "offline", false, "answer from -cache-dir only, without network, model or API key")
+	flag.BoolVar(&o.truncateQuestion, "truncate-question", false, "cut qaFlow questions longer than MAX_QUESTION_CHARS instead of rejecting them")
+	flag.StringVar(&o.teams, "teams", "", "JSON array or newline-separated file of team names to tag in the context")
+	flag.BoolVar(&o.verbose, "verbose", false, "log per-feed fetch, retry and skip details (debug level)")
+	flag.StringVar(&o.feeds, "feeds", "", "YAML or JSON file listing the feeds (default: built-in list)")
+	questionSet := false
+	flag.Func("question", "question asked to qaFlow instead of running the demo (or pass it as arguments)", func(s string) error {
+		o.question, questionSet = s, true
+		return nil
+	})
+	flag.Parse()
+	if o.maxItems < 1 {
+		return o, fmt.Errorf("the number of items per feed must be a positive integer (-max-items / GENKIT_MAX_ITEMS), got %d", o.maxItems)
+	}
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["temperature"] {
+		temperature = nil
+	}
+	if !set["max-tokens"] {
+		maxTokens = nil
+	}
+	if o.genConfig, err = newGenerationConfig(temperature, maxTokens); err != nil {
+		return o, err
+	}
+	if o.offline && o.cacheDir == "" {
+		return o, errors.New("-offline needs -cache-dir")
+	}
+	o.lang = strings.ToLower(strings.TrimSpace(o.lang))
+	if _, ok := cyclingPromptTemplates[o.lang]; !ok && o.lang != "" {
+		return o, fmt.Errorf("unsupported answer language %q (-lang): use fr or en", o.lang)
+	}
+	if flag.NArg() > 0 {
+		if questionSet {
+			return o, errors.New("give the question either with -question or as arguments, not both")
+		}
+		o.question, questionSet = strings.Join(flag.Args(), " "), true
+	}
+	o.question = strings.TrimSpace(o.question)
+	if questionSet && o.question == "" {
+		return o, errors.New("the question must not be empty (-question or arguments)")
+	}
+	return o, nil
+}
+
+// ErrMissingAPIKey is returned by CheckEnvironment when no Google AI API key is configured.
+var ErrMissingAPIKey = errors.New("no Google AI API key: set GOOGLE_API_KEY in the environment (get one at https://ai.google.dev)")
+
+// CheckEnvironment verifies, before any flow runs, that the Google AI plugin will find an API key.
+// The plugin reads GEMINI_API_KEY first, then GOOGLE_API_KEY.
+func CheckEnvironment() error {
+	if envOr("GEMINI_API_KEY", "") == "" && envOr("GOOGLE_API_KEY", "") == "" {
+		return ErrMissingAPIKey
+	}
+	return nil
+}
+
+// newGenerationConfig builds the model config for -temperature and -max-tokens, nil when they are
+// both unset. The Google AI plugin leaves out a zero temperature, so 0 is sent as minTemperature.
+func newGenerationConfig(temperature *float64, maxTokens *int) (*googlegenai.GeminiConfig, error) {
+	if temperature == nil && maxTokens == nil {
+		return nil, nil
+	}
+	cfg := &googlegenai.GeminiConfig{}
+	if temperature != nil {
+		if *temperature < 0 || *temperature > 2 {
+			return nil, fmt.Errorf("the temperature must be between 0 and 2 (-temperature), got %g", *temperature)
+		}
+		cfg.Temperature = max(*temperature, minTemperature)
+	}
+	if maxTokens != nil {
+		if *maxTokens < 1 {
+			return nil, fmt.Errorf("the maximum output tokens must be a positive integer (-max-tokens), got %d", *maxTokens)
+		}
+		cfg.MaxOutputTokens = *maxTokens
+	}
+	return cfg, nil
+}
+
+// envOr returns the trimmed value of the environment variable key, or def when it is unset or blank.
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// envInt parses the environment variable key as an integer, returning def when it is unset or blank.
+func envInt(key string, def int) (int, error) {
+	v := envOr(key, "")
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an integer", key, v)
+	}
+	return n, nil
+}
+
+// envBool parses the environment variable key as a boolean, returning def when it is unset or blank.
+func envBool(key string, def bool) (bool, error) {
+	v := envOr(key, "")
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %q is not a boolean", key, v)
+	}
+	return b, nil
+}
+
+// envFloat parses the environment variable key as a float64, returning def when it is unset or blank.
+func envFloat(key string, def float64) (float64, error) {
+	v := envOr(key, "")
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", key, v)
+	}
+	return f, nil
+}
+
+// envDuration parses the environment variable key as a time.Duration, returning def when it is unset or blank.
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	v := envOr(key, "")
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a duration", key, v)
+	}
+	return d, nil
+}
+
+// envList splits the environment variable key on commas, trimming entries.
+// It returns def when the variable is unset or blank.
+func envList(key string, def []string) []string {
+	v := envOr(key, "")
+	if v == "" {
+		return def
+	}
+	var list []string
+	for _, e := range strings.Split(v, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// envLowerList is envList with entries lowercased, for case-insensitive names such as hosts.
+func envLowerList(key string, def []string) []string {
+	list := envList(key, def)
+	if os.Getenv(key) == "" {
+		return list
+	}
+	for i, e := range list {
+		list[i] = strings.ToLower(e)
+	}
+	return list
+}
+
+// loadEnvConfig reads the optional environment variables documented in the README into the package settings.
+func loadEnvConfig() error {
+	var err error
+	if feedMergeOrder, err = parseMergeOrder(envOr("MERGE_ORDER", string(mergeRecency))); err != nil {
+		return err
+	}
+
+	maxFeeds, err := envInt("MAX_FEEDS_PER_REQUEST", 0)
+	if err != nil {
+		return err
+	}
+	if maxFeeds < 0 {
+		return fmt.Errorf("MAX_FEEDS_PER_REQUEST must not be negative, got %d", maxFeeds)
+	}
+	maxFeedsPerRequest = maxFeeds
+
+	if feedHTTPMode, err = parseHTTPMode(envOr("FEED_HTTP_MODE", string(httpAllow))); err != nil {
+		return err
+	}
+
+	if degradedDisclaimerEnabled, err = envBool("DEGRADED_DISCLAIMER_ENABLED", degradedDisclaimerEnabled); err != nil {
+		return err
+	}
+	degradedDisclaimer = envOr("DEGRADED_DISCLAIMER", degradedDisclaimer)
+
+	if feedShuffle, err = envBool("FEED_SHUFFLE", false); err != nil {
+		return err
+	}
+	seed, err := envInt("FEED_SHUFFLE_SEED", 0)
+	if err != nil {
+		return err
+	}
+	feedShuffleSeed = int64(seed)
+
+	if feedClient.Timeout, err = envDuration("FEED_TIMEOUT", feedClient.Timeout); err != nil {
+		return err
+	}
+	if feedClient.Timeout <= 0 {
+		return fmt.Errorf("FEED_TIMEOUT must be positive, got %s", feedClient.Timeout)
+	}
+	if enrichConcurrency, err = envInt("ENRICH_CONCURRENCY", enrichConcurrency); err != nil {
+		return err
+	}
+	if enrichConcurrency < 1 {
+		return fmt.Errorf("ENRICH_CONCURRENCY must be at least 1, got %d", enrichConcurrency)
+	}
+
+	feedUserAgent = envOr("FEED_USER_AGENT", feedUserAgent)
+	feedAllowedHosts = envLowerList("FEED_ALLOWED_HOSTS", nil)
+	if qaAnswers.size, err = envInt("QA_CACHE_SIZE", qaAnswers.size); err != nil {
+		return err
+	}
+	if qaAnswers.size < 0 {
+		return fmt.Errorf("QA_CACHE_SIZE must not be negative, got %d", qaAnswers.size)
+	}
+	if qaAnswers.ttl, err = envDuration("QA_CACHE_TTL", qaAnswers.ttl); err != nil {
+		return err
+	}
+	if maxQuestionChars, err = envInt("MAX_QUESTION_CHARS", maxQuestionChars); err != nil {
+		return err
+	}
+	if maxQuestionChars < 0 {
+		return fmt.Errorf("MAX_QUESTION_CHARS must not be negative, got %d", maxQuestionChars)
+	}
+	rate, err := envFloat("FEED_RATE_LIMIT", 0)
+	if err != nil {
+		return err
+	}
+	if rate < 0 {
+		return fmt.Errorf("FEED_RATE_LIMIT must not be negative, got %g", rate)
+	}
+	setFeedRate(rate)
+
+	if costPer1KInputTokens, err = envFloat("MODEL_COST_PER_1K_INPUT_TOKENS", costPer1KInputTokens); err != nil {
+		return err
+	}
+	if costPer1KOutputTokens, err = envFloat("MODEL_COST_PER_1K_OUTPUT_TOKENS", costPer1KOutputTokens); err != nil {
+		return err
+	}
+
+	rerankEmbedderName = envOr("RERANK_EMBEDDER", rerankEmbedderName)
+	if rerankTopN, err = envInt("RERANK_TOP_N", rerankTopN); err != nil {
+		return err
+	}
+	if rerankTopN < 1 {
+		return fmt.Errorf("RERANK_TOP_N must be at least 1, got %d", rerankTopN)
+	}
+
+	if flowDeadline, err = envDuration("FLOW_TIMEOUT", flowDeadline); err != nil {
+		return err
+	}
+	if flowDeadline <= 0 {
+		return fmt.Errorf("FLOW_TIMEOUT must be positive, got %s", flowDeadline)
+	}
+	if feedBreaker.threshold, err = envInt("FEED_BREAKER_THRESHOLD", feedBreaker.threshold); err != nil {
+		return err
+	}
+	if feedBreaker.threshold < 0 {
+		return fmt.Errorf("FEED_BREAKER_THRESHOLD must not be negative, got %d", feedBreaker.threshold)
+	}
+	if feedBreaker.cooldown, err = envDuration("FEED_BREAKER_COOLDOWN", feedBreaker.cooldown); err != nil {
+		return err
+	}
+	if feedBreaker.cooldown <= 0 {
+		return fmt.Errorf("FEED_BREAKER_COOLDOWN must be positive, got %s", feedBreaker.cooldown)
+	}
+	if shutdownGrace, err = envDuration("SHUTDOWN_GRACE", shutdownGrace); err != nil {
+		return err
+	}
+
+	dnsCacheOn, err := envBool("DNS_CACHE", false)
+	if err != nil {
+		return err
+	}
+	dnsCacheTTL, err := envDuration("DNS_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return err
+	}
+	if dnsCacheOn {
+		feedClient.Transport = newDNSCachingTransport(dnsCacheTTL)
+	}
+
+	if maxContextChars, err = envInt("MAX_CONTEXT_CHARS", maxContextChars); err != nil {
+		return err
+	}
+	if maxContextChars < 0 {
+		return fmt.Errorf("MAX_CONTEXT_CHARS must not be negative, got %d", maxContextChars)
+	}
+
+	cacheTTL, err := envDuration("FEED_CACHE_TTL", itemCache.ttl)
+	if err != nil {
+		return err
+	}
+	itemCache = newFeedCache(cacheTTL)
+
+	if summaryMode, err = parseSummaryMode(envOr("SUMMARY_MODE", summaryModel)); err != nil {
+		return err
+	}
+
+	if critiquePass, err = envBool("CRITIQUE_PASS", false); err != nil {
+		return err
+	}
+
+	if normalizeTitles, err = envBool("NORMALIZE_WHITESPACE", normalizeTitles); err != nil {
+		return err
+	}
+
+	if transferMatchMode, err = parseMatchMode(envOr("MATCH_MODE", string(matchAny))); err != nil {
+		return err
+	}
+	if transferMatchThreshold, err = envFloat("MATCH_THRESHOLD", transferMatchThreshold); err != nil {
+		return err
+	}
+	if !(transferMatchThreshold > 0) || math.IsInf(transferMatchThreshold, 1) {
+		return fmt.Errorf("MATCH_THRESHOLD must be a positive number, got %g", transferMatchThreshold)
+	}
+
+	if emptyResponseRetries, err = envInt("EMPTY_RESPONSE_RETRIES", emptyResponseRetries); err != nil {
+		return err
+	}
+	if emptyResponseRetries < 0 {
+		return fmt.Errorf("EMPTY_RESPONSE_RETRIES must not be negative, got %d", emptyResponseRetries)
+	}
+
+	if fingerprintShiftThreshold, err = envFloat("FINGERPRINT_SHIFT_THRESHOLD", fingerprintShiftThreshold); err != nil {
+		return err
+	}
+
+	if feedMaxAttempts, err = envInt("FEED_MAX_ATTEMPTS", feedMaxAttempts); err != nil {
+		return err
+	}
+	if feedMaxAttempts < 1 {
+		return fmt.Errorf("FEED_MAX_ATTEMPTS must be at least 1, got %d", feedMaxAttempts)
+	}
+
+	if stripTrackingParams, err = envBool("STRIP_TRACKING_PARAMS", stripTrackingParams); err != nil {
+		return err
+	}
+
+	fallbackModels = envList("MODEL_FALLBACKS", nil)
+
+	if modelMaxAttempts, err = envInt("MODEL_MAX_ATTEMPTS", modelMaxAttempts); err != nil {
+		return err
+	}
+	if modelMaxAttempts < 1 {
+		return fmt.Errorf("MODEL_MAX_ATTEMPTS must be at least 1, got %d", modelMaxAttempts)
+	}
+
+	if requireLink, err = envBool("REQUIRE_LINK", false); err != nil {
+		return err
+	}
+
+	if maxTitleChars, err = envInt("SNIPPET_TITLE_MAX_CHARS", 0); err != nil {
+		return err
+	}
+	if maxTitleChars < 0 {
+		return fmt.Errorf("SNIPPET_TITLE_MAX_CHARS must not be negative, got %d", maxTitleChars)
+	}
+	truncationMarker = envOr("TRUNCATION_MARKER", truncationMarker)
+
+	redactedParams = envLowerList("REDACT_URL_PARAMS", redactedParams)
+
+	if cyclingFeeds, err = checkDuplicateFeedURLs(cyclingFeeds, envOr("FEED_DUPLICATE_URLS", duplicatesWarn)); err != nil {
+		return err
+	}
+	return nil
+}
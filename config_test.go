@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvConfigListCase(t *testing.T) {
+	savedHosts, savedModels, savedParams := feedAllowedHosts, fallbackModels, redactedParams
+	t.Cleanup(func() { feedAllowedHosts, fallbackModels, redactedParams = savedHosts, savedModels, savedParams })
+
+	t.Setenv("FEED_ALLOWED_HOSTS", " Example.COM ,www.lequipe.fr")
+	t.Setenv("MODEL_FALLBACKS", "googleai/Gemini-2.0-Flash-Lite, vertexai/gemini-1.5-pro")
+	t.Setenv("REDACT_URL_PARAMS", "Token,X-Signature")
+	if err := loadEnvConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"example.com", "www.lequipe.fr"}; !slices.Equal(feedAllowedHosts, want) {
+		t.Errorf("feedAllowedHosts = %q, want %q", feedAllowedHosts, want)
+	}
+	if want := []string{"googleai/Gemini-2.0-Flash-Lite", "vertexai/gemini-1.5-pro"}; !slices.Equal(fallbackModels, want) {
+		t.Errorf("fallbackModels = %q, want %q", fallbackModels, want)
+	}
+	if want := []string{"token", "x-signature"}; !slices.Equal(redactedParams, want) {
+		t.Errorf("redactedParams = %q, want %q", redactedParams, want)
+	}
+}
+
+func TestLoadEnvConfigRejectsOutOfRangeValues(t *testing.T) {
+	savedThreshold, savedRetries, savedDeadline := transferMatchThreshold, emptyResponseRetries, flowDeadline
+	savedTimeout, savedBreakerThreshold, savedCooldown := feedClient.Timeout, feedBreaker.threshold, feedBreaker.cooldown
+	restore := func() {
+		transferMatchThreshold, emptyResponseRetries, flowDeadline = savedThreshold, savedRetries, savedDeadline
+		feedClient.Timeout, feedBreaker.threshold, feedBreaker.cooldown = savedTimeout, savedBreakerThreshold, savedCooldown
+	}
+	t.Cleanup(restore)
+	tests := []struct{ key, value string }{
+		{"EMPTY_RESPONSE_RETRIES", "-1"},
+		{"MATCH_THRESHOLD", "0"},
+		{"MATCH_THRESHOLD", "-2"},
+		{"MATCH_THRESHOLD", "NaN"},
+		{"MATCH_THRESHOLD", "+Inf"},
+		{"SNIPPET_TITLE_MAX_CHARS", "-5"},
+		{"FLOW_TIMEOUT", "0s"},
+		{"FLOW_TIMEOUT", "-1s"},
+		{"FEED_TIMEOUT", "0s"},
+		{"FEED_TIMEOUT", "-10s"},
+		{"FEED_BREAKER_THRESHOLD", "-1"},
+		{"FEED_BREAKER_COOLDOWN", "0s"},
+		{"FEED_BREAKER_COOLDOWN", "-5m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			// loadEnvConfig leaves a rejected value in place; reset it so the next case starts clean.
+			t.Cleanup(restore)
+			t.Setenv(tt.key, tt.value)
+			err := loadEnvConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("loadEnvConfig() = %v, want an error naming %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		gemini, google string
+		wantErr        error
+	}{
+		{"", "", ErrMissingAPIKey},
+		{"", "key", nil},
+		{"key", "", nil},
+	}
+	for _, tt := range tests {
+		t.Setenv("GEMINI_API_KEY", tt.gemini)
+		t.Setenv("GOOGLE_API_KEY", tt.google)
+		if err := CheckEnvironment(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("GEMINI_API_KEY=%q GOOGLE_API_KEY=%q: CheckEnvironment() = %v, want %v", tt.gemini, tt.google, err, tt.wantErr)
+		}
+	}
+}
+
+// parseArgs runs parseFlags on args with a fresh flag set, as if they were the command line.
+func parseArgs(t *testing.T, args ...string) (cliOptions, error) {
+	t.Helper()
+	savedFlags, savedArgs := flag.CommandLine, os.Args
+	t.Cleanup(func() { flag.CommandLine, os.Args = savedFlags, savedArgs })
+	flag.CommandLine = flag.NewFlagSet("genkit-programmez", flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	os.Args = append([]string{"genkit-programmez"}, args...)
+	return parseFlags()
+}
+
+func TestParseFlagsMaxItems(t *testing.T) {
+	tests := []struct {
+		env     string
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{"", nil, defaultMaxItemsPerFeed, false},
+		{"7", nil, 7, false},
+		{"7", []string{"-max-items", "3"}, 3, false},
+		{"", []string{"-max-items", "0"}, 0, true},
+		{"lots", nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("GENKIT_MAX_ITEMS", tt.env)
+		o, err := parseArgs(t, tt.args...)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && o.maxItems != tt.want) {
+			t.Errorf("GENKIT_MAX_ITEMS=%q %q: maxItems %d, err %v; want %d, error %v", tt.env, tt.args, o.maxItems, err, tt.want, tt.wantErr)
+		}
+	}
+}
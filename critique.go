@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// critiquePass enables a second Generate call that checks the RAG answer against its context (CRITIQUE_PASS).
+var critiquePass = false
+
+// critiqueAnswer asks the model to verify draft against contextBlock and to remove or fix unsupported claims.
+// When the second call fails or returns nothing, the draft is kept.
+func critiqueAnswer(ctx context.Context, gen Generator, contextBlock, question, draft string) (string, GenerateResult) {
+	prompt := fmt.Sprintf(
+		"Tu es un relecteur rigoureux.\n"+
+			"Contexte issu de flux d'actualités :\n%s\n\n"+
+			"Question : %s\n\n"+
+			"Réponse proposée :\n%s\n\n"+
+			"Vérifie chaque mutation de la réponse proposée à l'aide du contexte uniquement. "+
+			"Supprime ou corrige toute affirmation qu'il ne soutient pas, sans en ajouter de nouvelles. "+
+			"Renvoie seulement la réponse corrigée, au même format.",
+		contextBlock, question, draft,
+	)
+
+	res, err := gen.Generate(ctx, GenerateRequest{Model: modelName, Prompt: prompt})
+	if err != nil {
+		slog.Warn("critique pass failed, keeping first answer", "err", err)
+		return draft, GenerateResult{}
+	}
+	revised := strings.TrimSpace(res.Text)
+	if revised == "" {
+		slog.Warn("critique pass returned an empty answer, keeping first answer")
+		return draft, res
+	}
+	return revised, res
+}
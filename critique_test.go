@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestCritiqueDropsTheMutationsItRemoves(t *testing.T) {
+	serveFeed(t, testFeed)
+	saved := critiquePass
+	critiquePass = true
+	t.Cleanup(func() { critiquePass = saved })
+
+	draft, _ := json.Marshal(mutationReport{
+		Answer: "- Pogačar — UAE -> Cofidis\n- Vingegaard — Visma -> Movistar",
+		Mutations: []Mutation{
+			{Rider: "Tadej Pogačar", FromTeam: "UAE", ToTeam: "Cofidis", Status: statusRumour},
+			{Rider: "Jonas Vingegaard", FromTeam: "Visma", ToTeam: "Movistar", Status: statusRumour},
+		},
+	})
+	gen := &stubGenerator{reply: func(req GenerateRequest) (GenerateResult, error) {
+		if req.OutputType != nil {
+			return GenerateResult{Text: string(draft), Model: "stub"}, nil
+		}
+		return GenerateResult{Text: "- Pogačar — UAE -> Cofidis", Model: "stub"}, nil
+	}}
+
+	out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+	if err != nil {
+		t.Fatalf("RunFeedRAG: %v", err)
+	}
+	if out.Answer != "- Pogačar — UAE -> Cofidis" {
+		t.Errorf("Answer = %q, want the revised answer", out.Answer)
+	}
+	if len(out.Mutations) != 1 || out.Mutations[0].Rider != "Tadej Pogačar" {
+		t.Errorf("Mutations = %+v, want only the move kept by the critique", out.Mutations)
+	}
+}
+
+func TestMutationsInAnswer(t *testing.T) {
+	mutations := []Mutation{{Rider: "Tadej Pogačar"}, {Rider: "Primož Roglič"}, {Rider: ""}}
+	got := mutationsInAnswer(mutations, "**POGACAR** rejoint Cofidis ; Roglicienne n'est pas un coureur.")
+	if len(got) != 1 || got[0].Rider != "Tadej Pogačar" {
+		t.Errorf("mutationsInAnswer = %+v, want Pogačar alone", got)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// flowDeadline bounds a whole cyclingRAG or verifyTransferClaim run, feed fetching and model calls
+// included (FLOW_TIMEOUT, which must be positive); 0 means none.
+var flowDeadline = 60 * time.Second
+
+// withFlowDeadline derives a context that expires after flowDeadline, if one is set.
+func withFlowDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if flowDeadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, flowDeadline)
+}
+
+// deadlineError names the stage that was running when ctx's deadline passed. Other errors,
+// including caller cancellations, are returned unchanged.
+func deadlineError(ctx context.Context, stage string, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("flow exceeded its %s deadline (FLOW_TIMEOUT) while %s: %w", flowDeadline, stage, context.DeadlineExceeded)
+}
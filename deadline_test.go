@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCyclingRAGDeadline(t *testing.T) {
+	saved := flowDeadline
+	flowDeadline = 50 * time.Millisecond
+	t.Cleanup(func() { flowDeadline = saved })
+
+	t.Run("fetching feeds", func(t *testing.T) {
+		srv, _ := slowServer(t)
+		savedFeeds := cyclingFeeds
+		cyclingFeeds = []feedConfig{{name: "lent", urls: []string{srv.URL + "/rss"}, lang: "fr"}}
+		t.Cleanup(func() { cyclingFeeds = savedFeeds })
+
+		_, err := defineCyclingRAGFlow(newTestGenkit(t), &stubGenerator{}).Run(context.Background(), CyclingRAGInput{Refresh: true})
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "while fetching feeds") {
+			t.Errorf("got %v, want a deadline error naming the feed stage", err)
+		}
+	})
+
+	t.Run("generating the answer", func(t *testing.T) {
+		serveFeed(t, testFeed)
+		gen := &stubGenerator{reply: func(GenerateRequest) (GenerateResult, error) {
+			time.Sleep(2 * flowDeadline)
+			return GenerateResult{}, context.DeadlineExceeded
+		}}
+		_, err := defineCyclingRAGFlow(newTestGenkit(t), gen).Run(context.Background(), CyclingRAGInput{Refresh: true})
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrModel) || !strings.Contains(err.Error(), "while generating the answer") {
+			t.Errorf("got %v, want a model deadline error naming the generation stage", err)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeTitle is the key used to spot the same headline across feeds: lowercased, trimmed,
+// inner whitespace collapsed and trailing punctuation removed.
+func normalizeTitle(title string) string {
+	t := collapseWhitespace(strings.ToLower(title))
+	return strings.TrimRightFunc(t, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// dedupeItems drops items whose normalized title already appeared, keeping the first occurrence.
+func dedupeItems(items []rssItem) []rssItem {
+	seen := make(map[string]bool, len(items))
+	var out []rssItem
+	for _, it := range items {
+		key := normalizeTitle(it.Title)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, it)
+	}
+	return out
+}
+
+// dedupeStrings removes repeated values while preserving first-seen order.
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDedupeItemsByNormalizedTitle(t *testing.T) {
+	items := []rssItem{
+		{Title: "Transfert : Ayuso rejoint Lidl-Trek", Feed: "velo"},
+		{Title: "  transfert :  AYUSO rejoint Lidl-Trek !", Feed: "sport"},
+		{Title: "Transfert : Ayuso rejoint Lidl-Trek ?", Feed: "sport"},
+		{Title: "Ayuso rejoint Lidl-Trek", Feed: "sport"},
+	}
+	got := dedupeItems(items)
+	if len(got) != 2 || got[0].Feed != "velo" || got[1].Title != "Ayuso rejoint Lidl-Trek" {
+		t.Errorf("dedupeItems = %+v, want the first occurrence and the distinct title", got)
+	}
+}
+
+func TestFeedContextDedupesAcrossFeeds(t *testing.T) {
+	srv := serveFeeds(t, []feedConfig{
+		{name: "velo", urls: []string{"/velo"}, lang: "fr"},
+		{name: "sport", urls: []string{"/sport"}, lang: "fr"},
+	}, map[string]string{
+		"/velo": `<rss><channel>
+<item><title>Transfert : Ayuso rejoint Lidl-Trek</title><link>https://example.com/ayuso</link></item>
+<item><title>Carapaz signe chez EF</title><link>https://example.com/carapaz?utm_source=velo</link></item>
+</channel></rss>`,
+		"/sport": `<rss><channel>
+<item><title>TRANSFERT : Ayuso rejoint Lidl-Trek.</title><link>https://example.com/ayuso-sport</link></item>
+<item><title>Mercato : Carapaz signe chez EF</title><link>https://example.com/carapaz?utm_source=sport</link></item>
+</channel></rss>`,
+	})
+	saved := feedMergeOrder
+	feedMergeOrder = mergeFeedPriority
+	t.Cleanup(func() { feedMergeOrder = saved })
+
+	snippets, sources, _, err := fetchFeedContext(context.Background(), contextOptions{Refresh: true})
+	if err != nil {
+		t.Fatalf("fetchFeedContext: %v", err)
+	}
+	if len(snippets) != 3 {
+		t.Errorf("snippets = %q, want the Ayuso headline once", snippets)
+	}
+	wantSources := []string{
+		"https://example.com/ayuso",
+		"https://example.com/carapaz",
+		srv.URL + "/velo",
+		srv.URL + "/sport",
+	}
+	if !slices.Equal(sources, wantSources) {
+		t.Errorf("sources = %q, want %q", sources, wantSources)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// On-disk cache for reproducible demos: with -cache-dir, every downloaded feed and every model
+// answer is written there as JSON; with -offline, they are read back instead of using the
+// network or the model.
+var (
+	cacheDir    string
+	offlineMode = false
+)
+
+// errNotCached is returned in -offline mode when the cache has no entry for a feed or a prompt.
+var errNotCached = errors.New("not in the -cache-dir cache (-offline)")
+
+// cachedFeed is the file written for each feed URL.
+type cachedFeed struct {
+	URL       string      `json:"url"`
+	FetchedAt time.Time   `json:"fetchedAt"`
+	Items     []cachedRSS `json:"items"`
+}
+
+// cachedRSS mirrors rssItem, whose fields only carry XML tags.
+type cachedRSS struct {
+	Title   string `json:"title"`
+	Link    string `json:"link,omitempty"`
+	PubDate string `json:"pubDate,omitempty"`
+}
+
+// cachedAnswer is the file written for each model request.
+type cachedAnswer struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Text   string `json:"text"`
+}
+
+// cacheFile returns the path of the dir/kind entry for key, named after the SHA-256 of key so
+// that the same feed URL or prompt always maps to the same file.
+func cacheFile(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(cacheDir, kind, hex.EncodeToString(sum[:])+".json")
+}
+
+// writeCacheFile stores v as JSON at path, through a temporary file so readers never see a
+// partial entry.
+func writeCacheFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readCacheFile decodes the JSON entry at path into v, returning errNotCached when it does not exist.
+func readCacheFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errNotCached
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("reading cache entry %s: %w", path, err)
+	}
+	return nil
+}
+
+// saveFeedToDisk records the items downloaded from feedURL; failures are only logged.
+func saveFeedToDisk(feedURL string, items []rssItem) {
+	entry := cachedFeed{URL: feedURL, FetchedAt: time.Now(), Items: make([]cachedRSS, 0, len(items))}
+	for _, it := range items {
+		entry.Items = append(entry.Items, cachedRSS{Title: it.Title, Link: it.Link, PubDate: it.PubDate})
+	}
+	if err := writeCacheFile(cacheFile("feeds", feedURL), entry); err != nil {
+		slog.Warn("caching feed on disk failed", "url", redactURL(feedURL), "err", err)
+	}
+}
+
+// loadFeedFromDisk returns at most limit items recorded for feedURL.
+func loadFeedFromDisk(feedURL string, limit int) ([]rssItem, error) {
+	var entry cachedFeed
+	if err := readCacheFile(cacheFile("feeds", feedURL), &entry); err != nil {
+		return nil, fmt.Errorf("feed %s: %w", redactURL(feedURL), err)
+	}
+	items := make([]rssItem, 0, len(entry.Items))
+	for _, it := range entry.Items {
+		items = append(items, rssItem{Title: it.Title, Link: it.Link, PubDate: it.PubDate})
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// cachingGenerator records the answers of next in cacheDir, keyed by model, prompt and requested
+// output type. With offline set, it answers from the cache only and next is not used.
+type cachingGenerator struct {
+	next    Generator
+	offline bool
+}
+
+func (c cachingGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	path := cacheFile("answers", fmt.Sprintf("%s\x00%T\x00%s", req.Model, req.OutputType, req.Prompt))
+	if c.offline {
+		var entry cachedAnswer
+		if err := readCacheFile(path, &entry); err != nil {
+			return GenerateResult{}, fmt.Errorf("model answer: %w", err)
+		}
+		if req.Stream != nil {
+			if err := req.Stream(ctx, entry.Text); err != nil {
+				return GenerateResult{}, err
+			}
+		}
+		return GenerateResult{Text: entry.Text, Model: entry.Model}, nil
+	}
+
+	res, err := c.next.Generate(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	if err := writeCacheFile(path, cachedAnswer{Model: res.Model, Prompt: req.Prompt, Text: res.Text}); err != nil {
+		slog.Warn("caching model answer on disk failed", "err", err)
+	}
+	return res, nil
+}
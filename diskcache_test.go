@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCacheDirRoundTrip(t *testing.T) {
+	srv := serveFeed(t, testFeed)
+	savedDir, savedOffline := cacheDir, offlineMode
+	cacheDir = t.TempDir()
+	t.Cleanup(func() { cacheDir, offlineMode = savedDir, savedOffline })
+
+	online := &stubGenerator{reply: replyText("Pogačar rejoint Cofidis.", "googleai/test")}
+	in := CyclingRAGInput{Question: "Où va Pogačar ?", Refresh: true}
+	recorded, _, err := RunFeedRAG(context.Background(), cachingGenerator{next: online}, cyclingRAGConfig(), in)
+	if err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+	modelCalls := len(online.calls())
+	if modelCalls == 0 || len(recorded.Sources) == 0 {
+		t.Fatalf("recording run made %d model calls with sources %q, want both", modelCalls, recorded.Sources)
+	}
+
+	// Replay with the feed server gone and no model behind the cache.
+	srv.Close()
+	offlineMode = true
+	replayed, _, err := RunFeedRAG(context.Background(), cachingGenerator{offline: true}, cyclingRAGConfig(), in)
+	if err != nil {
+		t.Fatalf("offline run: %v", err)
+	}
+	if replayed.Answer != recorded.Answer || replayed.Model != recorded.Model {
+		t.Errorf("offline answer = %q from %q, want the recorded %q from %q", replayed.Answer, replayed.Model, recorded.Answer, recorded.Model)
+	}
+	if replayed.Degraded || !slices.Equal(replayed.Sources, recorded.Sources) {
+		t.Errorf("offline sources = %q (degraded %v), want the recorded %q", replayed.Sources, replayed.Degraded, recorded.Sources)
+	}
+	if n := len(online.calls()); n != modelCalls {
+		t.Errorf("the offline run called the model %d more times", n-modelCalls)
+	}
+
+	// Answers are keyed by prompt: another question has no recorded answer.
+	in.Question = "Où va Evenepoel ?"
+	if _, _, err := RunFeedRAG(context.Background(), cachingGenerator{offline: true}, cyclingRAGConfig(), in); !errors.Is(err, errNotCached) {
+		t.Errorf("offline run with a new prompt: err = %v, want %v", err, errNotCached)
+	}
+}
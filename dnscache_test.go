@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// loopbackResolver resolves every host to 127.0.0.1, counting lookups.
+type loopbackResolver struct {
+	lookups atomic.Int32
+}
+
+func (r *loopbackResolver) LookupHost(context.Context, string) ([]string, error) {
+	r.lookups.Add(1)
+	return []string{"127.0.0.1"}, nil
+}
+
+func TestDNSCacheReusesResolutionsUntilTheyExpire(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(srv.Close)
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	addr := net.JoinHostPort("feeds.test", port)
+
+	resolver := &loopbackResolver{}
+	c := newDNSCache(time.Hour)
+	c.resolver = resolver
+	dial := func() {
+		t.Helper()
+		conn, err := c.dialContext(context.Background(), "tcp", addr)
+		if err != nil {
+			t.Fatalf("dial %s: %v", addr, err)
+		}
+		conn.Close()
+	}
+
+	dial()
+	dial()
+	if n := resolver.lookups.Load(); n != 1 {
+		t.Errorf("two dials within the TTL made %d lookups, want 1", n)
+	}
+
+	c.mu.Lock()
+	e := c.entries["feeds.test"]
+	e.expires = time.Now().Add(-time.Second)
+	c.entries["feeds.test"] = e
+	c.mu.Unlock()
+	dial()
+	if n := resolver.lookups.Load(); n != 2 {
+		t.Errorf("a dial after expiry made %d lookups in total, want 2", n)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// runDryRun gathers the context for in exactly as cyclingRAG does and writes the resulting prompt
+// and sources to w, without calling the model.
+func runDryRun(ctx context.Context, w io.Writer, in CyclingRAGInput) error {
+	cfg := cyclingRAGConfig()
+	question := cfg.question(in.Question)
+	evType, err := parseEventType(in.EventTypeFilter)
+	if err != nil {
+		return err
+	}
+
+	snippets, sources, _, err := cyclingContext(ctx, cfg, in, question, evType)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "---- Prompt (%s) ----\n%s\n", modelName, cyclingPrompt(cfg, strings.Join(snippets, "\n"), question))
+	fmt.Fprintln(w, "---- Sources ----")
+	for _, src := range sources {
+		fmt.Fprintln(w, src)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDryRunPrintsThePromptWithoutCallingTheModel(t *testing.T) {
+	serveFeed(t, testFeed)
+	g := newTestGenkit(t)
+	model, calls := defineTextModel(g, "dry", `{"answer":"","mutations":[]}`)
+	saved := modelName
+	modelName = model
+	t.Cleanup(func() { modelName = saved })
+
+	for _, question := range []string{"Où va Pogačar ?", "   "} {
+		var out bytes.Buffer
+		if err := runDryRun(context.Background(), &out, CyclingRAGInput{Question: question, Refresh: true}); err != nil {
+			t.Fatalf("runDryRun(%q): %v", question, err)
+		}
+		want := cyclingRAGConfig().question(question)
+		for _, s := range []string{"Question : " + want + "\n", "Pogačar rejoint Cofidis", "https://example.com/a\n"} {
+			if !strings.Contains(out.String(), s) {
+				t.Errorf("question %q: output lacks %q:\n%s", question, s, out.String())
+			}
+		}
+	}
+	if *calls != 0 {
+		t.Errorf("the model was called %d times", *calls)
+	}
+}
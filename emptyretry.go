@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// errEmptyResponse is returned when the model keeps answering with blank text.
+var errEmptyResponse = errors.New("model returned an empty response")
+
+// emptyResponseRetries is how many extra attempts are made after a blank answer (EMPTY_RESPONSE_RETRIES).
+var emptyResponseRetries = 1
+
+// emptyRetryGenerator retries calls whose answer is empty or whitespace-only.
+type emptyRetryGenerator struct {
+	next    Generator
+	retries int
+}
+
+func (e emptyRetryGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := e.next.Generate(ctx, req)
+		if err != nil || strings.TrimSpace(res.Text) != "" {
+			return res, err
+		}
+		if attempt >= e.retries {
+			return GenerateResult{}, errEmptyResponse
+		}
+		slog.Info("empty answer, retrying", "model", req.Model, "attempt", attempt+1, "retries", e.retries)
+	}
+}
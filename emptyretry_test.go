@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEmptyRetryGenerator(t *testing.T) {
+	replies := func(texts ...string) func(GenerateRequest) (GenerateResult, error) {
+		return func(req GenerateRequest) (GenerateResult, error) {
+			text := texts[0]
+			if len(texts) > 1 {
+				texts = texts[1:]
+			}
+			return GenerateResult{Text: text, Model: req.Model}, nil
+		}
+	}
+
+	gen := &stubGenerator{reply: replies(" \n\t", "Pogačar reste chez UAE.")}
+	res, err := emptyRetryGenerator{next: gen, retries: 1}.Generate(context.Background(), GenerateRequest{Model: "stub"})
+	if err != nil || res.Text != "Pogačar reste chez UAE." || len(gen.calls()) != 2 {
+		t.Errorf("empty then non-empty: got %q, %v after %d calls; want the second answer after 2", res.Text, err, len(gen.calls()))
+	}
+
+	gen = &stubGenerator{reply: replies("")}
+	_, err = emptyRetryGenerator{next: gen, retries: 2}.Generate(context.Background(), GenerateRequest{Model: "stub"})
+	if !errors.Is(err, errEmptyResponse) || len(gen.calls()) != 3 {
+		t.Errorf("always empty: got %v after %d calls, want errEmptyResponse after 3", err, len(gen.calls()))
+	}
+
+	gen = &stubGenerator{reply: replies("")}
+	_, err = emptyRetryGenerator{next: gen, retries: 0}.Generate(context.Background(), GenerateRequest{Model: "stub"})
+	if !errors.Is(err, errEmptyResponse) || len(gen.calls()) != 1 {
+		t.Errorf("no retries: got %v after %d calls, want errEmptyResponse after 1", err, len(gen.calls()))
+	}
+}
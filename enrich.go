@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Article enrichment (-enrich): each context item gets the first paragraph of its article page.
+var (
+	enrichArticles    = false
+	enrichConcurrency = 4   // ENRICH_CONCURRENCY
+	enrichMaxChars    = 300 // length cap of an excerpt, cut at a word boundary
+)
+
+// maxArticleBytes bounds how much of an article page is read while looking for its first paragraph.
+const maxArticleBytes = 1 << 20
+
+// enrichItems fills the Excerpt of each transfer item (see isTransferItem) from its article page,
+// fetching at most enrichConcurrency pages at a time. Items whose page cannot be fetched keep only
+// their title.
+func enrichItems(ctx context.Context, items []rssItem) []rssItem {
+	out := make([]rssItem, len(items))
+	copy(out, items)
+
+	sem := make(chan struct{}, max(enrichConcurrency, 1))
+	var wg sync.WaitGroup
+	for i := range out {
+		if out[i].Link == "" || !isTransferItem(out[i]) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			excerpt, err := fetchArticleExcerpt(ctx, out[i].Link)
+			if err != nil {
+				slog.Warn("article enrichment failed, keeping the title only", "url", redactURL(out[i].Link), "err", err)
+				return
+			}
+			out[i].Excerpt = excerpt
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// fetchArticleExcerpt downloads the HTML page at link and returns its first paragraph.
+func fetchArticleExcerpt(ctx context.Context, link string) (string, error) {
+	target, err := applyHTTPMode(link, feedHTTPMode)
+	if err != nil {
+		return "", err
+	}
+	if err := checkFeedURL(target); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", feedUserAgent)
+
+	resp, err := feedClient.Do(req)
+	if err != nil {
+		return "", redactURLError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &statusError{code: resp.StatusCode}
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "" && mediaType != "text/html" {
+		return "", fmt.Errorf("not an HTML page (%s)", mediaType)
+	}
+
+	excerpt := firstParagraph(io.LimitReader(resp.Body, maxArticleBytes))
+	if excerpt == "" {
+		return "", fmt.Errorf("no paragraph found")
+	}
+	return truncateText(excerpt, enrichMaxChars, truncationMarker), nil
+}
+
+// firstParagraph returns the whitespace-collapsed text of the first non-empty <p> element of an
+// HTML document, ignoring script and style contents. It returns "" when there is none.
+func firstParagraph(r io.Reader) string {
+	z := html.NewTokenizer(r)
+	var text strings.Builder
+	inParagraph, skipDepth := false, 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken:
+			switch name, _ := z.TagName(); string(name) {
+			case "p":
+				inParagraph = true
+				text.Reset()
+			case "script", "style":
+				skipDepth++
+			}
+		case html.EndTagToken:
+			switch name, _ := z.TagName(); string(name) {
+			case "p":
+				if p := collapseWhitespace(text.String()); inParagraph && p != "" {
+					return p
+				}
+				inParagraph = false
+			case "script", "style":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			}
+		case html.TextToken:
+			if inParagraph && skipDepth == 0 {
+				text.Write(z.Text())
+				text.WriteByte(' ')
+			}
+		}
+	}
+}
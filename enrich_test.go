@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEnrichmentFetchesOnlyBudgetedTransferItems(t *testing.T) {
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/article/") {
+			mu.Lock()
+			fetched[r.URL.Path]++
+			mu.Unlock()
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><p>Premier paragraphe.</p></html>"))
+			return
+		}
+		w.Write([]byte(`<rss><channel>
+<item><title>Transfert : Pogačar rejoint Cofidis</title><link>` + srv.URL + `/article/new</link><pubDate>Tue, 03 Jun 2025 10:00:00 +0200</pubDate></item>
+<item><title>Transfert : Roglič rejoint Arkéa</title><link>` + srv.URL + `/article/old</link><pubDate>Mon, 02 Jun 2025 10:00:00 +0200</pubDate></item>
+</channel></rss>`))
+	}))
+	t.Cleanup(srv.Close)
+
+	savedEnrich, savedMax := enrichArticles, maxContextChars
+	t.Cleanup(func() { enrichArticles, maxContextChars = savedEnrich, savedMax })
+	enrichArticles = true
+	// Room for the newest item and its excerpt only.
+	maxContextChars = snippetSize(rssItem{Title: "Transfert : Pogačar rejoint Cofidis", PubDate: "Tue, 03 Jun 2025 10:00:00 +0200", Excerpt: "Premier paragraphe."})
+
+	snippets, _, _, err := fetchFeedContext(context.Background(), contextOptions{
+		Feeds:   []feedConfig{{name: "test", urls: []string{srv.URL + "/rss"}}},
+		Refresh: true,
+	})
+	if err != nil {
+		t.Fatalf("fetchFeedContext: %v", err)
+	}
+	if !strings.HasSuffix(snippets[0], ": Premier paragraphe.") {
+		t.Errorf("the kept item was not enriched: %q", snippets[0])
+	}
+	if fetched["/article/new"] != 1 || fetched["/article/old"] != 0 {
+		t.Errorf("article fetches = %v, want only the item kept by the budget", fetched)
+	}
+}
+
+func TestEnrichItemsSkipsNonTransferItems(t *testing.T) {
+	var hits int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.Write([]byte("<p>texte</p>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	out := enrichItems(context.Background(), []rssItem{{Title: "Résultats du Tour", Link: srv.URL + "/a"}})
+	if hits != 0 || out[0].Excerpt != "" {
+		t.Errorf("a non-transfer item was enriched (%d fetches, excerpt %q)", hits, out[0].Excerpt)
+	}
+}
+
+func TestDropOverflowingExcerpts(t *testing.T) {
+	items := []rssItem{
+		{Title: "ancien", PubDate: "Mon, 02 Jun 2025 10:00:00 +0200", Excerpt: "extrait ancien"},
+		{Title: "récent", PubDate: "Tue, 03 Jun 2025 10:00:00 +0200", Excerpt: "extrait récent"},
+	}
+	withNewest := snippetSize(items[0]) - len(" : extrait ancien") + snippetSize(items[1])
+	got := dropOverflowingExcerpts(items, withNewest)
+	if got[1].Excerpt == "" || got[0].Excerpt != "" {
+		t.Errorf("got %+v, want only the newest excerpt kept", got)
+	}
+	if items[0].Excerpt == "" {
+		t.Error("dropOverflowingExcerpts modified its argument")
+	}
+}
+
+func TestFirstParagraph(t *testing.T) {
+	tests := []struct{ html, want string }{
+		{"<p>  Un   deux </p><p>trois</p>", "Un deux"},
+		{"<p></p><script>var p;</script><p>suite</p>", "suite"},
+		{"<div>aucun paragraphe</div>", ""},
+	}
+	for _, tt := range tests {
+		if got := firstParagraph(strings.NewReader(tt.html)); got != tt.want {
+			t.Errorf("firstParagraph(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
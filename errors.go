@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by the flows, to be matched with errors.Is.
+var (
+	// ErrNoFeedsReachable reports that every feed failed and the caller asked not to fall back
+	// to the generic context (CyclingRAGInput.RequireFeeds).
+	ErrNoFeedsReachable = errors.New("no feed reachable")
+	// ErrModel wraps every failure of a model call.
+	ErrModel = errors.New("model call failed")
+	// ErrInvalidQuestion reports a qaFlow question that is blank or, without -truncate-question,
+	// longer than maxQuestionChars.
+	ErrInvalidQuestion = errors.New("invalid question")
+)
+
+// modelError marks err as coming from the model; nil stays nil.
+func modelError(err error) error {
+	if err == nil || errors.Is(err, ErrModel) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrModel, err)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunFeedRAGErrorKinds(t *testing.T) {
+	quota := errors.New("quota exceeded")
+	failing := &stubGenerator{reply: func(GenerateRequest) (GenerateResult, error) { return GenerateResult{}, quota }}
+
+	serveFeed(t, testFeed)
+	_, _, err := RunFeedRAG(context.Background(), failing, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+	if !errors.Is(err, ErrModel) || !errors.Is(err, quota) || errors.Is(err, ErrNoFeedsReachable) {
+		t.Errorf("model failure: err = %v, want ErrModel wrapping the cause", err)
+	}
+
+	srv := serveFeed(t, testFeed)
+	srv.Close()
+	calls := len(failing.calls())
+	_, _, err = RunFeedRAG(context.Background(), failing, cyclingRAGConfig(), CyclingRAGInput{Refresh: true, RequireFeeds: true})
+	if !errors.Is(err, ErrNoFeedsReachable) || errors.Is(err, ErrModel) {
+		t.Errorf("feeds down with RequireFeeds: err = %v, want ErrNoFeedsReachable", err)
+	}
+	if len(failing.calls()) != calls {
+		t.Error("the model was called although no feed was reachable")
+	}
+}
+
+func TestModelError(t *testing.T) {
+	if err := modelError(nil); err != nil {
+		t.Errorf("modelError(nil) = %v, want nil", err)
+	}
+	once := modelError(errors.New("boom"))
+	if twice := modelError(once); twice != once || strings.Count(twice.Error(), ErrModel.Error()) != 1 {
+		t.Errorf("modelError wrapped an ErrModel again: %v", twice)
+	}
+}
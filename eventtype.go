@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// eventType is the kind of transfer an item reports.
+type eventType string
+
+const (
+	eventSigning   eventType = "signing"
+	eventExtension eventType = "extension"
+	eventDeparture eventType = "departure"
+	eventLoan      eventType = "loan"
+)
+
+// eventTypeKeywords maps title keywords to event types. Checked in order, so more specific types come first.
+var eventTypeKeywords = []struct {
+	typ      eventType
+	keywords []string
+}{
+	{eventExtension, []string{"prolong", "renouvel", "extension", "extends"}},
+	{eventLoan, []string{"prêté", "en prêt", "on loan"}},
+	{eventDeparture, []string{"quitte", "départ", "leaves", "retraite"}},
+}
+
+func parseEventType(s string) (eventType, error) {
+	switch t := eventType(strings.ToLower(strings.TrimSpace(s))); t {
+	case "":
+		return "", nil
+	case eventSigning, eventExtension, eventDeparture, eventLoan:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid event type %q (want %s, %s, %s or %s)", s, eventSigning, eventExtension, eventDeparture, eventLoan)
+}
+
+// classifyEventType guesses the event type of a transfer title. Ambiguous titles are treated as signings.
+func classifyEventType(title string) eventType {
+	lower := strings.ToLower(title)
+	for _, entry := range eventTypeKeywords {
+		for _, kw := range entry.keywords {
+			if strings.Contains(lower, kw) {
+				return entry.typ
+			}
+		}
+	}
+	return eventSigning
+}
+
+// filterEventType keeps the items classified as typ. An empty typ keeps everything.
+func filterEventType(items []rssItem, typ eventType) []rssItem {
+	if typ == "" {
+		return items
+	}
+	var filtered []rssItem
+	for _, it := range items {
+		if classifyEventType(it.Title) == typ {
+			filtered = append(filtered, it)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyEventType(t *testing.T) {
+	tests := map[string]eventType{
+		"Julian Alaphilippe prolonge avec Cofidis":   eventExtension,
+		"Pogačar renouvelle son contrat":             eventExtension,
+		"Mads Pedersen quitte Lidl-Trek":             eventDeparture,
+		"Un jeune Belge prêté à une équipe de Conti": eventLoan,
+		"Remco Evenepoel rejoint Red Bull":           eventSigning,
+	}
+	for title, want := range tests {
+		if got := classifyEventType(title); got != want {
+			t.Errorf("classifyEventType(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestFilterEventType(t *testing.T) {
+	items := []rssItem{
+		{Title: "Julian Alaphilippe prolonge avec Cofidis"},
+		{Title: "Remco Evenepoel rejoint Red Bull"},
+	}
+	if got := filterEventType(items, eventExtension); !reflect.DeepEqual(got, items[:1]) {
+		t.Errorf("filterEventType(extension) = %+v, want the extension alone", got)
+	}
+	if got := filterEventType(items, ""); !reflect.DeepEqual(got, items) {
+		t.Errorf("filterEventType(\"\") = %+v, want every item", got)
+	}
+
+	if typ, err := parseEventType(" Extension "); err != nil || typ != eventExtension {
+		t.Errorf("parseEventType(Extension) = %q, %v; want %q", typ, err, eventExtension)
+	}
+	if _, err := parseEventType("transfer"); err == nil {
+		t.Error("parseEventType(transfer): want an error")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fallbackModels are tried in order after the requested model fails (MODEL_FALLBACKS).
+var fallbackModels []string
+
+// fallbackGenerator retries a failed call with each fallback model in turn. The model that
+// answered is reported in GenerateResult.Model.
+type fallbackGenerator struct {
+	next      Generator
+	fallbacks []string
+}
+
+func (f fallbackGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	streamed := false
+	if stream := req.Stream; stream != nil {
+		req.Stream = func(ctx context.Context, chunk string) error {
+			streamed = true
+			return stream(ctx, chunk)
+		}
+	}
+
+	var lastErr error
+	failed := ""
+	for i, model := range append([]string{req.Model}, f.fallbacks...) {
+		if i > 0 {
+			if model == req.Model {
+				continue
+			}
+			slog.Warn("modèle indisponible, essai du modèle de secours", "model", failed, "fallback", model, "err", lastErr)
+		}
+		attempt := req
+		attempt.Model = model
+		res, err := f.next.Generate(ctx, attempt)
+		if err == nil {
+			return res, nil
+		}
+		lastErr, failed = err, model
+		// The model answered, only not in the requested shape: that is for the caller to handle.
+		if errors.Is(err, errMalformedOutput) {
+			return res, err
+		}
+		// A caller that gave up, or already received part of an answer, gets no second one.
+		if streamed || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+	}
+	return GenerateResult{}, lastErr
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFallbackModelAnswersAfterThePrimaryFails(t *testing.T) {
+	gen := &stubGenerator{reply: func(req GenerateRequest) (GenerateResult, error) {
+		if req.Model == "googleai/gemini-2.0-flash" {
+			return GenerateResult{}, errors.New("model overloaded")
+		}
+		return GenerateResult{Text: "Réponse de secours.", Model: req.Model}, nil
+	}}
+	saved := modelName
+	modelName = "googleai/gemini-2.0-flash"
+	t.Cleanup(func() { modelName = saved })
+	summaries := captureLogs(t, "flowSummary")
+
+	fallback := fallbackGenerator{next: gen, fallbacks: []string{"googleai/gemini-2.0-flash", "googleai/gemini-2.0-flash-lite"}}
+	out, err := defineQAFlow(newTestGenkit(t), fallback).Run(context.Background(), QuestionInput{Question: "Qui a gagné le Tour ?"})
+	if err != nil {
+		t.Fatalf("qaFlow: %v", err)
+	}
+	if out.Answer != "Réponse de secours." || out.Model != "googleai/gemini-2.0-flash-lite" {
+		t.Errorf("got %+v, want the fallback model's answer", out)
+	}
+	var models []string
+	for _, c := range gen.calls() {
+		models = append(models, c.Model)
+	}
+	if len(models) != 2 {
+		t.Errorf("models tried = %v, want the primary once, then the fallback", models)
+	}
+	if got := summaries(); len(got) != 1 || got[0]["model"] != "googleai/gemini-2.0-flash-lite" {
+		t.Errorf("summaries = %v, want the responding fallback model", got)
+	}
+}
+
+func TestFallbackGeneratorReturnsTheLastError(t *testing.T) {
+	gen := &stubGenerator{reply: func(req GenerateRequest) (GenerateResult, error) {
+		return GenerateResult{}, errors.New(req.Model + " down")
+	}}
+	_, err := fallbackGenerator{next: gen, fallbacks: []string{"b", "c"}}.Generate(context.Background(), GenerateRequest{Model: "a"})
+	if err == nil || err.Error() != "c down" || len(gen.calls()) != 3 {
+		t.Errorf("got %v after %d calls, want the last fallback's error after 3", err, len(gen.calls()))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// feedCache keeps the items parsed from each feed URL for ttl, so repeated flow runs in
+// -serve mode do not refetch every feed. A zero ttl disables caching.
+type feedCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]feedCacheEntry
+}
+
+type feedCacheEntry struct {
+	items   []rssItem
+	fetched time.Time
+	expires time.Time
+}
+
+func newFeedCache(ttl time.Duration) *feedCache {
+	return &feedCache{ttl: ttl, entries: make(map[string]feedCacheEntry)}
+}
+
+// itemCache is the cache used by fetchFirstWorkingFeed (FEED_CACHE_TTL).
+var itemCache = newFeedCache(10 * time.Minute)
+
+// get returns a copy of the unexpired items cached for feedURL and when they were fetched.
+func (c *feedCache) get(feedURL string) ([]rssItem, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[feedURL]
+	if !ok || !time.Now().Before(e.expires) {
+		return nil, time.Time{}, false
+	}
+	return slices.Clone(e.items), e.fetched, true
+}
+
+func (c *feedCache) put(feedURL string, items []rssItem, fetched time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[feedURL] = feedCacheEntry{items: slices.Clone(items), fetched: fetched, expires: fetched.Add(c.ttl)}
+}
+
+// invalidate drops the entry for feedURL, or every entry when feedURL is empty.
+func (c *feedCache) invalidate(feedURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if feedURL == "" {
+		clear(c.entries)
+		return
+	}
+	delete(c.entries, feedURL)
+}
+
+// fetchCachedItems serves feedURL from c when possible and fetches it otherwise. With refresh set
+// the cached entry is ignored and replaced by a fresh fetch. fetched is when the items were
+// downloaded and cached reports whether they came from c.
+func (c *feedCache) fetchCachedItems(ctx context.Context, feedURL string, headers map[string]string, limit int, refresh bool) (items []rssItem, fetched time.Time, cached bool, err error) {
+	if refresh {
+		c.invalidate(feedURL)
+	} else if items, fetched, ok := c.get(feedURL); ok {
+		return items, fetched, true, nil
+	}
+	fetched = time.Now()
+	items, err = fetchRSSItems(ctx, feedURL, headers, limit)
+	if err == nil && len(items) > 0 {
+		c.put(feedURL, items, fetched)
+	}
+	return items, fetched, false, err
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingFeedServer starts a server answering with testFeed through handler, when non-nil, and
+// counts the requests it receives.
+func countingFeedServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	hits := new(atomic.Int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if handler != nil {
+			handler(w, r)
+			return
+		}
+		w.Write([]byte(testFeed))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hits
+}
+
+func TestFeedCacheTTLAndRefresh(t *testing.T) {
+	srv, hits := countingFeedServer(t, nil)
+	feedURL := srv.URL + "/rss"
+	c := newFeedCache(time.Hour)
+	ctx := context.Background()
+	fetch := func(refresh, wantCached bool, wantHits int32) {
+		t.Helper()
+		items, _, cached, err := c.fetchCachedItems(ctx, feedURL, nil, 10, refresh)
+		if err != nil || len(items) != 2 {
+			t.Fatalf("fetchCachedItems: %d items, %v", len(items), err)
+		}
+		if cached != wantCached || hits.Load() != wantHits {
+			t.Errorf("refresh=%v: cached=%v after %d requests, want cached=%v after %d", refresh, cached, hits.Load(), wantCached, wantHits)
+		}
+	}
+
+	fetch(false, false, 1)
+	fetch(false, true, 1) // within the TTL
+	fetch(true, false, 2) // Refresh bypasses the cache
+
+	c.mu.Lock()
+	e := c.entries[feedURL]
+	e.expires = time.Now().Add(-time.Second)
+	c.entries[feedURL] = e
+	c.mu.Unlock()
+	fetch(false, false, 3) // expired
+
+	disabled := newFeedCache(0)
+	for range 2 {
+		if _, _, cached, _ := disabled.fetchCachedItems(ctx, feedURL, nil, 10, false); cached {
+			t.Error("a zero TTL cache served cached items")
+		}
+	}
+	if n := hits.Load(); n != 5 {
+		t.Errorf("a zero TTL cache made %d requests in total, want 5", n)
+	}
+}
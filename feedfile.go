@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// feedFileEntry is one feed in a -feeds file. YAML being a superset of JSON, the same
+// decoder reads both formats.
+type feedFileEntry struct {
+	Name     string            `yaml:"name"`
+	URLs     []string          `yaml:"urls"`
+	Lang     string            `yaml:"lang"`
+	MaxItems int               `yaml:"maxItems"`
+	Headers  map[string]string `yaml:"headers"`
+	Weight   int               `yaml:"weight"`
+}
+
+// loadFeedsFile reads the feed list at path, a YAML or JSON array of feeds. Every feed needs at
+// least one URL; a missing name defaults to the first URL. Unknown keys are rejected, so a
+// misspelled option is not silently ignored.
+func loadFeedsFile(path string) ([]feedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading feeds: %w", err)
+	}
+	var entries []feedFileEntry
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&entries); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing feeds %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("feeds file %s has no entries", path)
+	}
+
+	feeds := make([]feedConfig, 0, len(entries))
+	for i, e := range entries {
+		var urls []string
+		for _, u := range e.URLs {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("feeds file %s: feed %d (%q) has no URL", path, i+1, e.Name)
+		}
+		if e.MaxItems < 0 {
+			return nil, fmt.Errorf("feeds file %s: feed %d (%q) has a negative maxItems", path, i+1, e.Name)
+		}
+		name := strings.TrimSpace(e.Name)
+		if name == "" {
+			name = urls[0]
+		}
+		feeds = append(feeds, feedConfig{
+			name:     name,
+			urls:     urls,
+			maxItems: e.MaxItems,
+			lang:     strings.ToLower(strings.TrimSpace(e.Lang)),
+			headers:  e.Headers,
+			weight:   e.Weight,
+		})
+	}
+	return feeds, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFeedsFileYAML(t *testing.T) {
+	path := writeTempFile(t, "flux.yaml", `
+- name: DirectVelo
+  urls: [" https://feeds.feedburner.com/ActualitsDirectvelo ", ""]
+  lang: FR
+  maxItems: 10
+  weight: 2
+  headers:
+    Accept: application/rss+xml
+- urls: [https://www.cyclingnews.com/feeds/rss/news/]
+`)
+	got, err := loadFeedsFile(path)
+	if err != nil {
+		t.Fatalf("loadFeedsFile: %v", err)
+	}
+	want := []feedConfig{
+		{name: "DirectVelo", urls: []string{"https://feeds.feedburner.com/ActualitsDirectvelo"}, lang: "fr", maxItems: 10, weight: 2,
+			headers: map[string]string{"Accept": "application/rss+xml"}},
+		{name: "https://www.cyclingnews.com/feeds/rss/news/", urls: []string{"https://www.cyclingnews.com/feeds/rss/news/"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadFeedsFile = %+v, want %+v", got, want)
+	}
+
+	jsonPath := writeTempFile(t, "flux.json", `[{"name": "DirectVelo", "urls": ["https://feeds.feedburner.com/ActualitsDirectvelo"]}]`)
+	if got, err := loadFeedsFile(jsonPath); err != nil || len(got) != 1 || got[0].name != "DirectVelo" {
+		t.Errorf("JSON file: %+v, %v; want the DirectVelo feed", got, err)
+	}
+}
+
+func TestLoadFeedsFileRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name, content, wantErr string
+	}{
+		{"unknown key", "- name: DirectVelo\n  url: https://example.com/rss\n", "field url not found"},
+		{"no URL", "- name: DirectVelo\n", "has no URL"},
+		{"negative maxItems", "- urls: [https://example.com/rss]\n  maxItems: -1\n", "negative maxItems"},
+		{"empty", "", "has no entries"},
+		{"not a list", "name: DirectVelo\n", "parsing feeds"},
+	}
+	for _, tt := range tests {
+		_, err := loadFeedsFile(writeTempFile(t, "flux.yaml", tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
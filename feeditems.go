@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// FeedItemsInput applies the same filters as CyclingRAGInput to the cyclingFeedItems flow.
+type FeedItemsInput struct {
+	// Question is what items are re-ranked against with -rerank; otherwise it is unused.
+	Question        string `json:"question,omitempty"`
+	EventTypeFilter string `json:"eventTypeFilter,omitempty"`
+	TeamFocus       string `json:"teamFocus,omitempty"`
+	Refresh         bool   `json:"refresh,omitempty"`
+}
+
+// FeedItem is one selected feed item. Link is empty when the item has no usable http(s) link.
+type FeedItem struct {
+	Title string `json:"title"`
+	Link  string `json:"link,omitempty"`
+	Date  string `json:"date,omitempty"`
+	Feed  string `json:"feed"`
+}
+
+// FeedItemsOutput lists the items cyclingRAG would build its context from.
+type FeedItemsOutput struct {
+	Items       []FeedItem `json:"items"`
+	FeedsOK     int        `json:"feedsOk"`
+	FeedsTotal  int        `json:"feedsTotal"`
+	ContextAsOf time.Time  `json:"contextAsOf"`
+}
+
+// defineFeedItemsFlow registers cyclingFeedItems, which returns the feed items selected by the
+// cyclingRAG pipeline without calling the model, so a front end can show sources cheaply.
+func defineFeedItemsFlow(g *genkit.Genkit) *core.Flow[FeedItemsInput, FeedItemsOutput, struct{}] {
+	return genkit.DefineFlow(g, "cyclingFeedItems",
+		func(ctx context.Context, in FeedItemsInput) (FeedItemsOutput, error) {
+			evType, err := parseEventType(in.EventTypeFilter)
+			if err != nil {
+				return FeedItemsOutput{}, err
+			}
+			cfg := cyclingRAGConfig()
+
+			items, _, stats, _, err := selectFeedItems(ctx, contextOptions{
+				Feeds:     cfg.Feeds,
+				Keywords:  cfg.Keywords,
+				EventType: evType,
+				Team:      strings.TrimSpace(in.TeamFocus),
+				Refresh:   in.Refresh,
+				Question:  cfg.question(in.Question),
+			})
+			if err != nil {
+				return FeedItemsOutput{}, err
+			}
+
+			out := FeedItemsOutput{
+				Items:       make([]FeedItem, 0, len(items)),
+				FeedsOK:     stats.FeedsOK,
+				FeedsTotal:  stats.FeedsTotal,
+				ContextAsOf: stats.ContextAsOf,
+			}
+			for _, it := range items {
+				link, _ := sanitizeSourceURL(it.Link)
+				out.Items = append(out.Items, FeedItem{Title: it.Title, Link: link, Date: it.PubDate, Feed: it.Feed})
+			}
+			return out, nil
+		},
+	)
+}
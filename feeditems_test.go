@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// serveFeeds starts a server answering each path of bodies with its feed, and points cyclingFeeds
+// at one feed per path, in the order given, for the duration of the test.
+func serveFeeds(t *testing.T, feeds []feedConfig, bodies map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	saved := cyclingFeeds
+	cyclingFeeds = nil
+	for _, feed := range feeds {
+		for i, u := range feed.urls {
+			feed.urls[i] = srv.URL + u
+		}
+		cyclingFeeds = append(cyclingFeeds, feed)
+	}
+	t.Cleanup(func() { cyclingFeeds = saved })
+	return srv
+}
+
+func TestFeedItemsFlowReturnsTheSelectedItems(t *testing.T) {
+	serveFeeds(t, []feedConfig{
+		{name: "velo", urls: []string{"/velo"}, lang: "fr", maxItems: 2},
+		{name: "sport", urls: []string{"/sport"}, lang: "fr"},
+	}, map[string]string{
+		"/velo": `<rss><channel>
+<item><title>Transfert : Ayuso rejoint Lidl-Trek</title><link>https://example.com/ayuso?utm_medium=rss</link><pubDate>Sun, 01 Jun 2025 10:00:00 +0000</pubDate></item>
+<item><title>Carapaz signe chez EF</title><link>https://example.com/carapaz</link><pubDate>Tue, 03 Jun 2025 10:00:00 +0000</pubDate></item>
+<item><title>Transfert : au-delà de la limite du flux</title><pubDate>Thu, 05 Jun 2025 10:00:00 +0000</pubDate></item>
+</channel></rss>`,
+		"/sport": `<rss><channel>
+<item><title>Mercato : Pidcock quitte Ineos</title><link>javascript:alert(1)</link><pubDate>Wed, 04 Jun 2025 10:00:00 +0000</pubDate></item>
+<item><title>Résultats de l'étape</title><pubDate>Thu, 05 Jun 2025 12:00:00 +0000</pubDate></item>
+</channel></rss>`,
+	})
+	saved := feedMergeOrder
+	feedMergeOrder = mergeRecency
+	t.Cleanup(func() { feedMergeOrder = saved })
+
+	out, err := defineFeedItemsFlow(newTestGenkit(t)).Run(context.Background(), FeedItemsInput{Refresh: true})
+	if err != nil {
+		t.Fatalf("cyclingFeedItems: %v", err)
+	}
+	want := []FeedItem{
+		{Title: "Mercato : Pidcock quitte Ineos", Date: "Wed, 04 Jun 2025 10:00:00 +0000", Feed: "sport"},
+		{Title: "Carapaz signe chez EF", Link: "https://example.com/carapaz", Date: "Tue, 03 Jun 2025 10:00:00 +0000", Feed: "velo"},
+		{Title: "Transfert : Ayuso rejoint Lidl-Trek", Link: "https://example.com/ayuso", Date: "Sun, 01 Jun 2025 10:00:00 +0000", Feed: "velo"},
+	}
+	if !reflect.DeepEqual(out.Items, want) {
+		t.Errorf("Items =\n%+v\nwant the newest transfer items first, within each feed's limit:\n%+v", out.Items, want)
+	}
+	if out.FeedsOK != 2 || out.FeedsTotal != 2 {
+		t.Errorf("FeedsOK/FeedsTotal = %d/%d, want 2/2", out.FeedsOK, out.FeedsTotal)
+	}
+}
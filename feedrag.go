@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// FeedRAGConfig describes a feed-driven RAG flow: which feeds to read, which title keywords mark a
+// relevant item, and how to ask the model.
+type FeedRAGConfig struct {
+	Feeds    []feedConfig
+	Keywords []string
+	// PromptTemplate is formatted with the context block and the question, in that order.
+	PromptTemplate string
+	// DefaultQuery is used when the caller's question is blank.
+	DefaultQuery string
+}
+
+// answerLanguage is the language cyclingRAG answers in (-lang), a key of cyclingPromptTemplates.
+// Empty uses the French prompt and picks the fallback messages from the question (see messagesLanguage).
+var answerLanguage = ""
+
+// cyclingPromptTemplates holds the cyclingRAG prompt for each answer language.
+var cyclingPromptTemplates = map[string]string{
+	"fr": "Tu es un assistant cyclisme.\n" +
+		"Contexte issu de flux d'actualités (mutations/transferts) :\n%s\n\n" +
+		"Question : %s\n" +
+		"Dans answer, réponds en français par une liste concise de mutations : Nom — équipe actuelle -> équipe annoncée (ou rumeur). " +
+		"Si l'équipe n'est pas précisée, indique 'vers équipe inconnue'. " +
+		"Dans mutations, reprends chaque mouvement avec le coureur, l'équipe d'origine, l'équipe de destination " +
+		"et le statut (officiel ou rumeur).",
+	"en": "You are a cycling assistant.\n" +
+		"Context from news feeds (transfers), possibly in French:\n%s\n\n" +
+		"Question: %s\n" +
+		"In answer, reply in English as a concise list of transfers: Name — current team -> announced team (or rumour). " +
+		"If the team is not given, write 'to unknown team'. " +
+		"In mutations, list each move with the rider, the team of origin, the destination team " +
+		"and the status (official or rumour).",
+}
+
+// cyclingPromptTemplate returns the cyclingRAG prompt for lang, defaulting to French.
+func cyclingPromptTemplate(lang string) string {
+	if t, ok := cyclingPromptTemplates[lang]; ok {
+		return t
+	}
+	return cyclingPromptTemplates[defaultLanguage]
+}
+
+// cyclingRAGConfig is the configuration behind cyclingRAG. It is built on each call because
+// cyclingFeeds, transferKeywords and answerLanguage can be replaced at startup.
+func cyclingRAGConfig() FeedRAGConfig {
+	return FeedRAGConfig{
+		Feeds:          cyclingFeeds,
+		Keywords:       transferKeywords,
+		PromptTemplate: cyclingPromptTemplate(answerLanguage),
+		DefaultQuery:   defaultCyclingQuery,
+	}
+}
+
+// question returns the trimmed question, or cfg.DefaultQuery when it is blank.
+func (cfg FeedRAGConfig) question(q string) string {
+	if q = strings.TrimSpace(q); q != "" {
+		return q
+	}
+	return cfg.DefaultQuery
+}
+
+// RunFeedRAG answers in.Question from the items of cfg.Feeds matching cfg.Keywords. It also
+// returns the feed statistics of the run, even on error.
+func RunFeedRAG(ctx context.Context, gen Generator, cfg FeedRAGConfig, in CyclingRAGInput) (CyclingRAGOutput, feedStats, error) {
+	question := cfg.question(in.Question)
+	evType, err := parseEventType(in.EventTypeFilter)
+	if err != nil {
+		return CyclingRAGOutput{}, feedStats{}, err
+	}
+
+	ctx, cancel := withFlowDeadline(ctx)
+	defer cancel()
+
+	team := canonicalTeam(in.TeamFocus)
+	msgs := messagesFor(messagesLanguage(question))
+	snippets, sources, stats, err := cyclingContext(ctx, cfg, in, question, evType)
+	if err == nil {
+		// Feed failures only degrade the context, so check whether the deadline cut the fetches short.
+		err = ctx.Err()
+	}
+	if err != nil {
+		return CyclingRAGOutput{}, stats, deadlineError(ctx, "fetching feeds", err)
+	}
+
+	var answer, usedModel string
+	var roster teamRoster
+	var mutations []Mutation
+	var usage TokenUsage
+	switch {
+	case summaryMode == summaryOffline:
+		answer = offlineSummary(msgs.OfflineHeader, snippets)
+		mutations = offlineMutations(stats.ContextItems)
+		usedModel = offlineModelName
+	case team != "" && stats.Reason == reasonNoTeamMatch:
+		answer = fmt.Sprintf(msgs.NoTeamNews, team)
+	case team != "":
+		r, res, err := generateTeamRoster(ctx, gen, strings.Join(snippets, "\n"), team)
+		if err != nil {
+			return CyclingRAGOutput{}, stats, modelError(deadlineError(ctx, "generating the team roster", err))
+		}
+		roster = *r
+		answer = roster.Summary
+		usedModel = res.Model
+		usage = usageOf(res)
+	default:
+		contextBlock := strings.Join(snippets, "\n")
+		report, res, err := generateMutations(ctx, gen, cyclingPrompt(cfg, contextBlock, question))
+		if err != nil {
+			return CyclingRAGOutput{}, stats, modelError(deadlineError(ctx, "generating the answer", err))
+		}
+		answer, mutations = report.Answer, report.Mutations
+		usedModel = res.Model
+		usage = usageOf(res)
+		if critiquePass {
+			revised, critique := critiqueAnswer(ctx, gen, contextBlock, question, answer)
+			if revised != answer {
+				mutations = mutationsInAnswer(mutations, revised)
+			}
+			answer = revised
+			usage = usage.add(usageOf(critique))
+		}
+	}
+
+	if stats.Degraded {
+		answer = withDegradedDisclaimer(answer, msgs)
+	}
+
+	out := CyclingRAGOutput{
+		Answer:      answer,
+		Model:       usedModel,
+		Mutations:   mutations,
+		Sources:     sources,
+		Degraded:    stats.Degraded,
+		ContextAsOf: stats.ContextAsOf,
+		Arrivals:    roster.Arrivals,
+		Departures:  roster.Departures,
+		TokenUsage:  usage,
+
+		NoTransfersFound: stats.Reason != "",
+		Reason:           stats.Reason,
+	}
+	if in.Verbose {
+		out.SucceededFeeds = stats.SucceededURLs
+		out.FailedFeeds = stats.FailedURLs
+	}
+	return out, stats, nil
+}
+
+// defineFeedRAGFlow registers a flow named name that runs RunFeedRAG with the config returned by
+// config, called on each run.
+func defineFeedRAGFlow(g *genkit.Genkit, gen Generator, name string, config func() FeedRAGConfig) *core.Flow[CyclingRAGInput, CyclingRAGOutput, struct{}] {
+	return genkit.DefineFlow(g, name,
+		func(ctx context.Context, in CyclingRAGInput) (out CyclingRAGOutput, err error) {
+			cfg := config()
+			start := time.Now()
+			var stats feedStats
+			defer func() {
+				model := out.Model
+				if err != nil {
+					model = modelName
+					if summaryMode == summaryOffline {
+						model = offlineModelName
+					}
+				}
+				logFlowSummary(flowSummary{
+					Flow: name, Question: cfg.question(in.Question),
+					FeedsOK: stats.FeedsOK, FeedsTotal: stats.FeedsTotal, Items: stats.Items,
+					Model: model, CacheHit: stats.FeedsOK > 0 && stats.FeedsCached == stats.FeedsOK,
+					Duration: time.Since(start), Err: err,
+				})
+			}()
+			out, stats, err = RunFeedRAG(ctx, gen, cfg, in)
+			return out, err
+		},
+	)
+}
+
+// withDegradedDisclaimer prefixes answer with the degraded-context disclaimer when it is enabled,
+// using DEGRADED_DISCLAIMER or else the localized default.
+func withDegradedDisclaimer(answer string, msgs fallbackMessages) string {
+	if !degradedDisclaimerEnabled {
+		return answer
+	}
+	disclaimer := degradedDisclaimer
+	if disclaimer == "" {
+		disclaimer = msgs.Disclaimer
+	}
+	return disclaimer + "\n\n" + answer
+}
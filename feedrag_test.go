@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunFeedRAGWithDummyConfig(t *testing.T) {
+	srv := serveFeed(t, `<rss><channel>
+<item><title>Mbappé signe au Real</title><link>https://example.com/foot</link></item>
+<item><title>Pogačar rejoint Cofidis</title><link>https://example.com/velo</link></item>
+</channel></rss>`)
+	cfg := FeedRAGConfig{
+		Feeds:          []feedConfig{{name: "foot", urls: []string{srv.URL + "/foot"}, lang: "fr"}},
+		Keywords:       []string{"signe"},
+		PromptTemplate: "CONTEXTE\n%s\nQUESTION %s",
+		DefaultQuery:   "Quels transferts au football ?",
+	}
+	gen := &stubGenerator{reply: replyText(`{"answer":"Mbappé — PSG -> Real","mutations":[]}`, "stub")}
+
+	out, _, err := RunFeedRAG(context.Background(), gen, cfg, CyclingRAGInput{Refresh: true})
+	if err != nil {
+		t.Fatalf("RunFeedRAG: %v", err)
+	}
+	if out.Answer != "Mbappé — PSG -> Real" || out.Degraded {
+		t.Errorf("got %+v, want the stub answer from a non-degraded context", out)
+	}
+	calls := gen.calls()
+	if len(calls) != 1 {
+		t.Fatalf("the generator was called %d times, want 1", len(calls))
+	}
+	prompt := calls[0].Prompt
+	if !strings.HasPrefix(prompt, "CONTEXTE\n") || !strings.HasSuffix(prompt, "QUESTION Quels transferts au football ?") {
+		t.Errorf("prompt does not follow the config template and default query:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Mbappé signe au Real") || strings.Contains(prompt, "Pogačar") {
+		t.Errorf("prompt should hold only the item matching the config keywords:\n%s", prompt)
+	}
+}
+
+func TestRunFeedRAGReportsNoTransfersFound(t *testing.T) {
+	gen := &stubGenerator{reply: replyText(`{"answer":"Aucun transfert.","mutations":[]}`, "stub")}
+	tests := []struct {
+		name       string
+		feed       string
+		wantNone   bool
+		wantReason string
+	}{
+		{"transfer item", testFeed, false, ""},
+		{"no transfer item", `<rss><channel><item><title>Résultats de la course</title></item></channel></rss>`, true, reasonNoTransferMatched},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveFeed(t, tt.feed)
+			out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+			if err != nil {
+				t.Fatalf("RunFeedRAG: %v", err)
+			}
+			if out.NoTransfersFound != tt.wantNone || out.Reason != tt.wantReason || out.Degraded {
+				t.Errorf("NoTransfersFound %v, Reason %q, Degraded %v; want %v, %q, false", out.NoTransfersFound, out.Reason, out.Degraded, tt.wantNone, tt.wantReason)
+			}
+		})
+	}
+
+	t.Run("no feed reachable", func(t *testing.T) {
+		srv := serveFeed(t, testFeed)
+		srv.Close()
+		out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+		if err != nil {
+			t.Fatalf("RunFeedRAG: %v", err)
+		}
+		if !out.NoTransfersFound || out.Reason != reasonNoFeedsReachable || !out.Degraded {
+			t.Errorf("NoTransfersFound %v, Reason %q, Degraded %v; want true, %q, true", out.NoTransfersFound, out.Reason, out.Degraded, reasonNoFeedsReachable)
+		}
+	})
+}
+
+func TestRunFeedRAGVerboseReportsFeedProvenance(t *testing.T) {
+	srv := serveFeeds(t, []feedConfig{
+		{name: "up", urls: []string{"/up"}, lang: "fr"},
+		{name: "down", urls: []string{"/down"}, lang: "fr"},
+	}, map[string]string{"/up": testFeed})
+	gen := &stubGenerator{reply: replyText(`{"answer":"Pogačar rejoint Cofidis.","mutations":[]}`, "stub")}
+
+	out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true, Verbose: true})
+	if err != nil {
+		t.Fatalf("RunFeedRAG: %v", err)
+	}
+	if !slices.Equal(out.SucceededFeeds, []string{srv.URL + "/up"}) || !slices.Equal(out.FailedFeeds, []string{srv.URL + "/down"}) {
+		t.Errorf("SucceededFeeds %q, FailedFeeds %q; want /up and /down", out.SucceededFeeds, out.FailedFeeds)
+	}
+
+	out, _, err = RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+	if err != nil || out.SucceededFeeds != nil || out.FailedFeeds != nil {
+		t.Errorf("without Verbose: SucceededFeeds %q, FailedFeeds %q, err %v; want neither", out.SucceededFeeds, out.FailedFeeds, err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// How duplicate URLs across feeds are handled at startup (FEED_DUPLICATE_URLS).
+const (
+	duplicatesWarn   = "warn"
+	duplicatesDedupe = "dedupe"
+)
+
+// checkDuplicateFeedURLs logs every URL listed by more than one feed. With dedupe set, later occurrences
+// are removed so each URL is attributed to the first feed listing it; feeds left without URLs are dropped.
+func checkDuplicateFeedURLs(feeds []feedConfig, mode string) ([]feedConfig, error) {
+	if mode != duplicatesWarn && mode != duplicatesDedupe {
+		return nil, fmt.Errorf("invalid duplicate URL mode %q (want %s or %s)", mode, duplicatesWarn, duplicatesDedupe)
+	}
+
+	// owner maps each URL to the index of the first feed listing it: feeds may share a name.
+	owner := make(map[string]int)
+	var out []feedConfig
+	for i, feed := range feeds {
+		var urls []string
+		for _, u := range feed.urls {
+			first, seen := owner[u]
+			if !seen {
+				owner[u] = i
+				urls = append(urls, u)
+				continue
+			}
+			if first == i {
+				slog.Warn("URL listée plusieurs fois par le même flux", "url", redactURL(u), "feed", feed.name)
+			} else {
+				slog.Warn("URL listée par plusieurs flux", "url", redactURL(u), "feed", feeds[first].name, "duplicate_feed", feed.name)
+			}
+			if mode == duplicatesWarn {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			slog.Warn("flux ignoré, toutes ses URL sont déjà utilisées", "feed", feed.name)
+			continue
+		}
+		feed.urls = urls
+		out = append(out, feed)
+	}
+	return out, nil
+}
+
+// byWeight returns a copy of feeds sorted by decreasing weight; feeds of equal weight keep their order.
+func byWeight(feeds []feedConfig) []feedConfig {
+	sorted := slices.Clone(feeds)
+	slices.SortStableFunc(sorted, func(a, b feedConfig) int { return cmp.Compare(b.weight, a.weight) })
+	return sorted
+}
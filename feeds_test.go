@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestMaxFeedsPerRequestKeepsTheHeaviestFeeds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss><channel><item><title>Transfert ` + r.URL.Path + `</title></item></channel></rss>`))
+	}))
+	t.Cleanup(srv.Close)
+	saved := maxFeedsPerRequest
+	maxFeedsPerRequest = 2
+	t.Cleanup(func() { maxFeedsPerRequest = saved })
+	warnings := captureLogs(t, "flux ignorés (MAX_FEEDS_PER_REQUEST)")
+
+	feeds := []feedConfig{
+		{name: "light", urls: []string{srv.URL + "/light"}},
+		{name: "first-heavy", urls: []string{srv.URL + "/first-heavy"}, weight: 5},
+		{name: "plain", urls: []string{srv.URL + "/plain"}},
+		{name: "second-heavy", urls: []string{srv.URL + "/second-heavy"}, weight: 5},
+	}
+	items, _, stats := gatherFeedItems(context.Background(), contextOptions{Feeds: feeds, Refresh: true})
+
+	if stats.FeedsTotal != 2 {
+		t.Errorf("FeedsTotal = %d, want the cap of 2", stats.FeedsTotal)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.Feed)
+	}
+	slices.Sort(got)
+	if want := []string{"first-heavy", "second-heavy"}; !slices.Equal(got, want) {
+		t.Errorf("items came from %v, want %v", got, want)
+	}
+	logged := warnings()
+	if len(logged) != 1 || logged[0]["feeds"] != "light, plain" {
+		t.Errorf("warnings = %v, want one naming the skipped feeds in order", logged)
+	}
+}
+
+func TestByWeightIsStable(t *testing.T) {
+	feeds := []feedConfig{{name: "a"}, {name: "b", weight: 1}, {name: "c"}, {name: "d", weight: 1}, {name: "e", weight: -1}}
+	var got []string
+	for _, f := range byWeight(feeds) {
+		got = append(got, f.name)
+	}
+	if want := []string{"b", "d", "a", "c", "e"}; !slices.Equal(got, want) {
+		t.Errorf("byWeight order = %v, want %v", got, want)
+	}
+	if feeds[0].name != "a" {
+		t.Error("byWeight sorted its argument in place")
+	}
+}
+
+func TestCheckDuplicateFeedURLsWording(t *testing.T) {
+	feeds := []feedConfig{
+		{name: "velo", urls: []string{"https://a.example/rss", "https://a.example/rss"}},
+		{name: "sport", urls: []string{"https://a.example/rss", "https://b.example/rss"}},
+	}
+	for _, tt := range []struct{ msg, duplicateFeed string }{
+		{"URL listée plusieurs fois par le même flux", ""},
+		{"URL listée par plusieurs flux", "sport"},
+	} {
+		warnings := captureLogs(t, tt.msg)
+		if _, err := checkDuplicateFeedURLs(feeds, duplicatesWarn); err != nil {
+			t.Fatal(err)
+		}
+		got := warnings()
+		if len(got) != 1 || got[0]["feed"] != "velo" {
+			t.Errorf("%q warnings = %v, want one for velo", tt.msg, got)
+			continue
+		}
+		if d, _ := got[0]["duplicate_feed"].(string); d != tt.duplicateFeed {
+			t.Errorf("%q: duplicate_feed = %q, want %q", tt.msg, d, tt.duplicateFeed)
+		}
+	}
+}
+
+func TestCheckDuplicateFeedURLsTellsFeedsApartByPosition(t *testing.T) {
+	warnings := captureLogs(t, "URL listée par plusieurs flux")
+	feeds := []feedConfig{
+		{name: "velo", urls: []string{"https://a.example/rss"}},
+		{name: "velo", urls: []string{"https://a.example/rss"}},
+	}
+	if _, err := checkDuplicateFeedURLs(feeds, duplicatesWarn); err != nil {
+		t.Fatal(err)
+	}
+	if got := warnings(); len(got) != 1 || got[0]["duplicate_feed"] != "velo" {
+		t.Errorf("warnings = %v, want one cross-feed warning for the two feeds named velo", got)
+	}
+}
+
+func TestCheckDuplicateFeedURLsModes(t *testing.T) {
+	feeds := []feedConfig{
+		{name: "velo", urls: []string{"https://a.example/rss", "https://b.example/rss", "https://a.example/rss"}},
+		{name: "sport", urls: []string{"https://b.example/rss", "https://c.example/rss"}},
+		{name: "copie", urls: []string{"https://c.example/rss"}},
+	}
+
+	kept, err := checkDuplicateFeedURLs(feeds, duplicatesWarn)
+	if err != nil || !slices.EqualFunc(kept, feeds, func(a, b feedConfig) bool { return a.name == b.name && slices.Equal(a.urls, b.urls) }) {
+		t.Errorf("warn: got %+v, %v; want the feeds unchanged", kept, err)
+	}
+
+	deduped, err := checkDuplicateFeedURLs(feeds, duplicatesDedupe)
+	if err != nil {
+		t.Fatalf("dedupe: %v", err)
+	}
+	want := []feedConfig{
+		{name: "velo", urls: []string{"https://a.example/rss", "https://b.example/rss"}},
+		{name: "sport", urls: []string{"https://c.example/rss"}},
+	}
+	if !slices.EqualFunc(deduped, want, func(a, b feedConfig) bool { return a.name == b.name && slices.Equal(a.urls, b.urls) }) {
+		t.Errorf("dedupe: got %+v, want %+v", deduped, want)
+	}
+
+	if _, err := checkDuplicateFeedURLs(feeds, "drop"); err == nil {
+		t.Error("an invalid mode was accepted")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fingerprintShiftThreshold is the vocabulary similarity (0–1) below which a feed is reported as
+// having changed content (FINGERPRINT_SHIFT_THRESHOLD). Regular news turnover stays well above it.
+var fingerprintShiftThreshold = 0.1
+
+// feedFingerprint summarizes the vocabulary of a feed's titles.
+type feedFingerprint struct {
+	// Hash identifies the vocabulary; equal hashes mean the same set of words.
+	Hash  string
+	words map[string]bool
+	// changedAt is when Hash was first recorded for the feed URL, set by recordFingerprint.
+	changedAt time.Time
+}
+
+// fingerprints remembers the last fingerprint seen per feed URL.
+var fingerprints = struct {
+	sync.Mutex
+	byURL map[string]feedFingerprint
+}{byURL: make(map[string]feedFingerprint)}
+
+// fingerprintItems builds the fingerprint of items from the significant words of their titles.
+func fingerprintItems(items []rssItem) feedFingerprint {
+	words := make(map[string]bool)
+	for _, it := range items {
+		for _, w := range strings.FieldsFunc(foldAccents(it.Title), isClaimSeparator) {
+			if len([]rune(w)) >= minClaimTokenLen {
+				words[w] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(words))
+	for w := range words {
+		sorted = append(sorted, w)
+	}
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, " ")))
+	return feedFingerprint{Hash: hex.EncodeToString(sum[:8]), words: words}
+}
+
+// recordFingerprint stores fp for feedURL and warns when it differs sharply from the previous one.
+// It returns the vocabulary similarity with the previous fingerprint, or 1 when there was none.
+func recordFingerprint(feedURL string, fp feedFingerprint) float64 {
+	fingerprints.Lock()
+	prev, ok := fingerprints.byURL[feedURL]
+	fp.changedAt = time.Now()
+	if ok && prev.Hash == fp.Hash {
+		fp.changedAt = prev.changedAt
+	}
+	fingerprints.byURL[feedURL] = fp
+	fingerprints.Unlock()
+
+	if !ok || prev.Hash == fp.Hash {
+		return 1
+	}
+	sim := jaccard(prev.words, fp.words)
+	if sim < fingerprintShiftThreshold {
+		slog.Warn("contenu du flux très différent du précédent",
+			"url", redactURL(feedURL), "similarity", sim, "previous", prev.Hash, "current", fp.Hash)
+	}
+	return sim
+}
+
+// lastFingerprint returns the fingerprint last recorded for feedURL and when it last changed.
+func lastFingerprint(feedURL string) (hash string, changedAt time.Time, ok bool) {
+	fingerprints.Lock()
+	defer fingerprints.Unlock()
+	fp, ok := fingerprints.byURL[feedURL]
+	return fp.Hash, fp.changedAt, ok
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for w := range a {
+		if b[w] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
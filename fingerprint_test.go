@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestRecordFingerprintWarnsOnContentShift(t *testing.T) {
+	const feedURL = "https://example.com/fingerprint-test?token=s3cret"
+	t.Cleanup(func() {
+		fingerprints.Lock()
+		delete(fingerprints.byURL, feedURL)
+		fingerprints.Unlock()
+	})
+	warnings := captureLogs(t, "contenu du flux très différent du précédent")
+
+	cycling := []rssItem{{Title: "Pogačar rejoint Cofidis"}, {Title: "Evenepoel prolonge chez Soudal"}}
+	turnover := []rssItem{{Title: "Pogačar rejoint Cofidis"}, {Title: "Ayuso quitte UAE"}}
+	recipes := []rssItem{{Title: "Recette du gratin dauphinois"}, {Title: "Tarte aux pommes facile"}}
+
+	steps := []struct {
+		name     string
+		items    []rssItem
+		wantWarn int
+	}{
+		{"first fetch", cycling, 0},
+		{"same titles", cycling, 0},
+		{"news turnover", turnover, 0},
+		{"unrelated content", recipes, 1},
+	}
+	for _, s := range steps {
+		sim := recordFingerprint(feedURL, fingerprintItems(s.items))
+		if n := len(warnings()); n != s.wantWarn {
+			t.Fatalf("%s: similarity %.2f, %d warnings so far, want %d", s.name, sim, n, s.wantWarn)
+		}
+	}
+	if got := warnings()[0]["url"]; got != "https://example.com/fingerprint-test?token="+redactedValue {
+		t.Errorf("warning url = %v, want it redacted", got)
+	}
+}
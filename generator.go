@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/firebase/genkit/go/plugins/googlegenai"
+)
+
+// Generator produces model output for a prompt. Flows depend on it rather than on genkit.Generate
+// so they can run against a scripted implementation without network access.
+type Generator interface {
+	Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error)
+}
+
+// GenerateRequest describes a single model call.
+type GenerateRequest struct {
+	Model  string
+	Prompt string
+	// Config is passed to the model as-is when non-nil (e.g. *googlegenai.GeminiConfig);
+	// genkitGenerator uses generationConfig otherwise.
+	Config any
+	// OutputType, when non-nil, requests JSON output matching the schema of its type.
+	OutputType any
+	// Stream, when non-nil, receives the answer text chunk by chunk as the model produces it.
+	Stream func(ctx context.Context, chunk string) error
+}
+
+// GenerateResult is the text of a model answer, the model that produced it and the usage reported with it, if any.
+type GenerateResult struct {
+	Text  string
+	Model string
+	Usage *ai.GenerationUsage
+}
+
+// generationConfig is the config of model calls that do not set their own (-temperature,
+// -max-tokens); nil keeps the model defaults.
+var generationConfig *googlegenai.GeminiConfig
+
+// minTemperature stands for -temperature 0: the Google AI plugin does not send a zero temperature,
+// which would then fall back to the model default instead of near-deterministic output.
+const minTemperature = 1e-6
+
+// genkitGenerator is the production Generator, backed by genkit.Generate.
+type genkitGenerator struct {
+	g *genkit.Genkit
+}
+
+func (gg genkitGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	opts := []ai.GenerateOption{
+		ai.WithModelName(req.Model),
+		ai.WithPrompt(req.Prompt),
+	}
+	switch {
+	case req.Config != nil:
+		opts = append(opts, ai.WithConfig(req.Config))
+	case generationConfig != nil:
+		opts = append(opts, ai.WithConfig(generationConfig))
+	}
+	// raw is the model's last answer as received, before genkit checks it against OutputType.
+	var raw *GenerateResult
+	if req.OutputType != nil {
+		opts = append(opts, ai.WithOutputType(req.OutputType), ai.WithMiddleware(captureRawAnswer(req.Model, &raw)))
+	}
+	if req.Stream != nil {
+		opts = append(opts, ai.WithStreaming(func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+			return req.Stream(ctx, chunk.Text())
+		}))
+	}
+	resp, err := genkit.Generate(ctx, gg.g, opts...)
+	if err != nil && raw != nil {
+		// The model answered, so genkit rejected the answer itself: it does not match the schema.
+		return *raw, fmt.Errorf("%w: %v", errMalformedOutput, err)
+	}
+	if err != nil {
+		return GenerateResult{}, err
+	}
+	return GenerateResult{Text: resp.Text(), Model: req.Model, Usage: resp.Usage}, nil
+}
+
+// captureRawAnswer is a model middleware storing in *raw the text and usage of each successful
+// answer, which genkit.Generate does not return when the answer fails output validation.
+func captureRawAnswer(model string, raw **GenerateResult) ai.ModelMiddleware {
+	return func(next ai.ModelFunc) ai.ModelFunc {
+		return func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			resp, err := next(ctx, req, cb)
+			if err == nil && resp != nil {
+				*raw = &GenerateResult{Text: resp.Text(), Model: model, Usage: resp.Usage}
+			}
+			return resp, err
+		}
+	}
+}
+
+// errMalformedOutput is returned when the model's answer does not decode as the requested type,
+// along with a GenerateResult holding the raw answer.
+var errMalformedOutput = errors.New("malformed model JSON output")
+
+// generateData runs req through gen requesting JSON output shaped like Out, and decodes it.
+func generateData[Out any](ctx context.Context, gen Generator, req GenerateRequest) (*Out, GenerateResult, error) {
+	var out Out
+	req.OutputType = out
+	res, err := gen.Generate(ctx, req)
+	if err != nil {
+		return nil, res, err
+	}
+	if err := json.Unmarshal([]byte(res.Text), &out); err != nil {
+		return nil, res, fmt.Errorf("%w: %v", errMalformedOutput, err)
+	}
+	return &out, res, nil
+}
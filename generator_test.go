@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/firebase/genkit/go/plugins/googlegenai"
+)
+
+// stubGenerator is a scripted Generator: it records every request and answers with reply, or
+// echoes the prompt when reply is nil.
+type stubGenerator struct {
+	reply func(GenerateRequest) (GenerateResult, error)
+
+	mu       sync.Mutex
+	requests []GenerateRequest
+}
+
+func (s *stubGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	if s.reply == nil {
+		return GenerateResult{Text: req.Prompt, Model: req.Model}, nil
+	}
+	return s.reply(req)
+}
+
+// calls returns the requests received so far.
+func (s *stubGenerator) calls() []GenerateRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]GenerateRequest(nil), s.requests...)
+}
+
+// replyText returns a stub reply answering every request with text from model.
+func replyText(text, model string) func(GenerateRequest) (GenerateResult, error) {
+	return func(GenerateRequest) (GenerateResult, error) {
+		return GenerateResult{Text: text, Model: model}, nil
+	}
+}
+
+// newTestGenkit returns a Genkit instance without plugins, for flows driven by a stubGenerator.
+func newTestGenkit(t *testing.T) *genkit.Genkit {
+	t.Helper()
+	g, err := genkit.Init(context.Background())
+	if err != nil {
+		t.Fatalf("genkit.Init: %v", err)
+	}
+	return g
+}
+
+// serveFeed starts a server answering every request with body and points cyclingFeeds at it for
+// the duration of the test.
+func serveFeed(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	saved := cyclingFeeds
+	cyclingFeeds = []feedConfig{{name: "test", urls: []string{srv.URL + "/rss"}, lang: "fr"}}
+	t.Cleanup(func() { cyclingFeeds = saved })
+	return srv
+}
+
+const testFeed = `<rss><channel>
+<item><title>Transfert : Pogačar rejoint Cofidis</title><link>https://example.com/a?utm_source=x</link><pubDate>Mon, 02 Jun 2025 10:00:00 +0200</pubDate></item>
+<item><title>Résultats de la course</title><link>https://example.com/b</link></item>
+</channel></rss>`
+
+func TestQAFlowReturnsGeneratorAnswer(t *testing.T) {
+	gen := &stubGenerator{reply: replyText("Quarante-deux.", "stub/fallback")}
+	flow := defineQAFlow(newTestGenkit(t), gen)
+
+	out, err := flow.Run(context.Background(), QuestionInput{Question: "  Quelle est la réponse ?  "})
+	if err != nil {
+		t.Fatalf("qaFlow: %v", err)
+	}
+	if out.Answer != "Quarante-deux." || out.Model != "stub/fallback" {
+		t.Errorf("got %+v, want the stub answer and model", out)
+	}
+	calls := gen.calls()
+	if len(calls) != 1 || calls[0].Prompt != "Quelle est la réponse ?" {
+		t.Errorf("prompts sent = %+v, want the trimmed question once", calls)
+	}
+}
+
+func TestCyclingRAGUsesFeedContextAndStructuredOutput(t *testing.T) {
+	serveFeed(t, testFeed)
+	report, _ := json.Marshal(mutationReport{
+		Answer:    "- Pogačar — UAE -> Cofidis",
+		Mutations: []Mutation{{Rider: "Tadej Pogačar", FromTeam: "UAE", ToTeam: "Cofidis", Status: "rumeur"}},
+	})
+	gen := &stubGenerator{reply: replyText(string(report), "stub")}
+	flow := defineCyclingRAGFlow(newTestGenkit(t), gen)
+
+	out, err := flow.Run(context.Background(), CyclingRAGInput{Question: "Quelles mutations ?", Refresh: true})
+	if err != nil {
+		t.Fatalf("cyclingRAG: %v", err)
+	}
+	if len(out.Mutations) != 1 || out.Mutations[0].ToTeam != "Cofidis" {
+		t.Errorf("Mutations = %+v, want the stub mutation", out.Mutations)
+	}
+	if out.Degraded || out.Model != "stub" {
+		t.Errorf("Degraded = %v, Model = %q; want a non-degraded answer from stub", out.Degraded, out.Model)
+	}
+	if len(out.Sources) == 0 || out.Sources[0] != "https://example.com/a" {
+		t.Errorf("Sources = %v, want the sanitized transfer link first", out.Sources)
+	}
+
+	calls := gen.calls()
+	if len(calls) == 0 {
+		t.Fatal("the generator was not called")
+	}
+	if calls[0].OutputType == nil {
+		t.Error("the mutation request did not ask for structured output")
+	}
+	if !strings.Contains(calls[0].Prompt, "Pogačar rejoint Cofidis") || strings.Contains(calls[0].Prompt, "Résultats de la course") {
+		t.Errorf("prompt should hold only the transfer item:\n%s", calls[0].Prompt)
+	}
+}
+
+func TestGenerationConfigReachesTheModel(t *testing.T) {
+	g := newTestGenkit(t)
+	var got any
+	genkit.DefineModel(g, "test", "capture", nil, func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		got = req.Config
+		return &ai.ModelResponse{Message: ai.NewModelTextMessage("ok")}, nil
+	})
+
+	temperature, maxTokens := 0.0, 128
+	cfg, err := newGenerationConfig(&temperature, &maxTokens)
+	if err != nil {
+		t.Fatalf("newGenerationConfig: %v", err)
+	}
+	saved := generationConfig
+	generationConfig = cfg
+	t.Cleanup(func() { generationConfig = saved })
+
+	if _, err := (genkitGenerator{g: g}).Generate(context.Background(), GenerateRequest{Model: "test/capture", Prompt: "?"}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	gc, ok := got.(*googlegenai.GeminiConfig)
+	if !ok {
+		t.Fatalf("the model received a %T config, want *googlegenai.GeminiConfig", got)
+	}
+	if gc.Temperature != minTemperature || gc.MaxOutputTokens != 128 {
+		t.Errorf("the model received temperature %g and max tokens %d, want %g and 128", gc.Temperature, gc.MaxOutputTokens, minTemperature)
+	}
+}
+
+func TestNewGenerationConfig(t *testing.T) {
+	ptr := func(f float64) *float64 { return &f }
+	if cfg, err := newGenerationConfig(nil, nil); cfg != nil || err != nil {
+		t.Errorf("without flags: got %+v, %v; want nil, nil", cfg, err)
+	}
+	if cfg, err := newGenerationConfig(ptr(0.7), nil); err != nil || cfg.Temperature != 0.7 || cfg.MaxOutputTokens != 0 {
+		t.Errorf("-temperature 0.7: got %+v, %v", cfg, err)
+	}
+	if _, err := newGenerationConfig(ptr(2.5), nil); err == nil {
+		t.Error("-temperature 2.5 was accepted")
+	}
+	zero := 0
+	if _, err := newGenerationConfig(nil, &zero); err == nil {
+		t.Error("-max-tokens 0 was accepted")
+	}
+}
+
+// defineTextModel registers a model named test/name answering every request with text, and
+// returns its full name and a counter of the calls it received.
+func defineTextModel(g *genkit.Genkit, name, text string) (string, *int) {
+	calls := new(int)
+	genkit.DefineModel(g, "test", name, nil, func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		*calls++
+		return &ai.ModelResponse{Message: ai.NewModelTextMessage(text)}, nil
+	})
+	return "test/" + name, calls
+}
+
+func TestGenerateMutationsFallsBackOnMalformedModelJSON(t *testing.T) {
+	g := newTestGenkit(t)
+	const reply = "- Pogačar — UAE -> Cofidis (rumeur)"
+	primary, primaryCalls := defineTextModel(g, "prose", reply)
+	backup, backupCalls := defineTextModel(g, "backup", `{"answer":"secours","mutations":[]}`)
+
+	savedModel := modelName
+	modelName = primary
+	t.Cleanup(func() { modelName = savedModel })
+	gen := fallbackGenerator{next: retryGenerator{next: genkitGenerator{g: g}, maxAttempts: 3}, fallbacks: []string{backup}}
+
+	report, res, err := generateMutations(context.Background(), gen, "Quelles mutations ?")
+	if err != nil {
+		t.Fatalf("generateMutations: %v", err)
+	}
+	if report.Answer != reply || len(report.Mutations) != 0 {
+		t.Errorf("report = %+v, want the raw answer and no mutations", report)
+	}
+	if res.Model != primary {
+		t.Errorf("Model = %q, want %q", res.Model, primary)
+	}
+	if *primaryCalls != 1 || *backupCalls != 0 {
+		t.Errorf("calls: primary %d, backup %d; want the malformed answer kept without retry or fallback", *primaryCalls, *backupCalls)
+	}
+}
@@ -2,7 +2,14 @@ module genkit-programmez
 
 go 1.24.1
 
-require github.com/firebase/genkit/go v0.5.0
+require (
+	github.com/firebase/genkit/go v0.5.0
+	golang.org/x/net v0.37.0
+	golang.org/x/text v0.23.0
+	golang.org/x/time v0.14.0
+	google.golang.org/genai v0.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	cloud.google.com/go v0.116.0 // indirect
@@ -34,12 +41,8 @@ require (
 	go.opentelemetry.io/otel/trace v1.29.0 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/exp v0.0.0-20240318143956-a85f2c67cd81 // indirect
-	golang.org/x/net v0.37.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
-	google.golang.org/genai v0.7.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
 	google.golang.org/grpc v1.66.2 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
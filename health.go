@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// feedHealth remembers the outcome of the latest feed refresh, for /readyz.
+type feedHealth struct {
+	mu         sync.Mutex
+	at         time.Time
+	feedsOK    int
+	feedsTotal int
+}
+
+// lastFeedRefresh is updated by every gatherFeedItems run.
+var lastFeedRefresh feedHealth
+
+func (h *feedHealth) record(feedsOK, feedsTotal int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.at, h.feedsOK, h.feedsTotal = time.Now(), feedsOK, feedsTotal
+}
+
+func (h *feedHealth) snapshot() (time.Time, int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.at, h.feedsOK, h.feedsTotal
+}
+
+// handleHealthz reports that the process is up.
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz answers 200 when an API key is configured and at least one feed responded during the
+// last refresh, and 503 with the reason otherwise.
+func handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	if err := CheckEnvironment(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	at, ok, total := lastFeedRefresh.snapshot()
+	switch {
+	case at.IsZero():
+		http.Error(w, "no feed refresh yet", http.StatusServiceUnavailable)
+	case ok == 0:
+		http.Error(w, fmt.Sprintf("no feed reachable at %s (0/%d)", at.Format(time.RFC3339), total), http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintf(w, "ok (%d/%d feeds at %s)\n", ok, total, at.Format(time.RFC3339))
+	}
+}
+
+// warmFeeds runs one feed refresh so /readyz has a result (and the cache is filled) before the first request.
+func warmFeeds(ctx context.Context) {
+	_, _, stats := gatherFeedItems(ctx, contextOptions{})
+	slog.Info("flux préchargés", "feeds_ok", stats.FeedsOK, "feeds_total", stats.FeedsTotal)
+}
+
+// feedStatus is one configured feed as reported by /feeds.
+type feedStatus struct {
+	Name string          `json:"name"`
+	URLs []feedURLStatus `json:"urls"`
+}
+
+// handleFeeds lists every configured feed with the last fetch time, item count and error of each
+// of its URLs, the state of their circuit breaker and their content fingerprint.
+func handleFeeds(w http.ResponseWriter, _ *http.Request) {
+	feeds := make([]feedStatus, 0, len(cyclingFeeds))
+	for _, feed := range cyclingFeeds {
+		st := feedStatus{Name: feed.name}
+		for _, u := range feed.urls {
+			us := feedBreaker.status(u)
+			if hash, changed, ok := lastFingerprint(u); ok {
+				us.Fingerprint, us.FingerprintChanged = hash, &changed
+			}
+			st.URLs = append(st.URLs, us)
+		}
+		feeds = append(feeds, st)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(feeds); err != nil {
+		slog.Warn("writing /feeds failed", "err", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedsReportsFingerprint(t *testing.T) {
+	srv := serveFeed(t, testFeed)
+	feedURL := srv.URL + "/rss"
+	status := func() feedURLStatus {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handleFeeds(rec, httptest.NewRequest("GET", "/feeds", nil))
+		var feeds []feedStatus
+		if err := json.NewDecoder(rec.Body).Decode(&feeds); err != nil {
+			t.Fatalf("decoding /feeds: %v", err)
+		}
+		if len(feeds) != 1 || len(feeds[0].URLs) != 1 {
+			t.Fatalf("/feeds = %+v, want the test feed alone", feeds)
+		}
+		return feeds[0].URLs[0]
+	}
+
+	for range 2 {
+		if _, _, _, _, err := fetchFirstWorkingFeed(context.Background(), []string{feedURL}, nil, 5, true); err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+	}
+	st := status()
+	want := fingerprintItems([]rssItem{{Title: "Transfert : Pogačar rejoint Cofidis"}, {Title: "Résultats de la course"}}).Hash
+	if st.Fingerprint != want || st.FingerprintChanged == nil {
+		t.Fatalf("fingerprint = %q changed %v, want %q with a change time", st.Fingerprint, st.FingerprintChanged, want)
+	}
+	if st.LastFetch == nil || !st.FingerprintChanged.Before(*st.LastFetch) {
+		t.Errorf("fingerprintChanged %v should predate the second, identical download at %v", st.FingerprintChanged, st.LastFetch)
+	}
+}
+
+func TestHealthzAndReadyz(t *testing.T) {
+	at, ok, total := lastFeedRefresh.snapshot()
+	t.Cleanup(func() {
+		lastFeedRefresh.mu.Lock()
+		defer lastFeedRefresh.mu.Unlock()
+		lastFeedRefresh.at, lastFeedRefresh.feedsOK, lastFeedRefresh.feedsTotal = at, ok, total
+	})
+
+	get := func(handler func(http.ResponseWriter, *http.Request), path string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	if code, body := get(handleHealthz, "/healthz"); code != http.StatusOK || body != "ok\n" {
+		t.Errorf("/healthz = %d %q, want 200 ok", code, body)
+	}
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		ok, all  int
+		wantCode int
+		wantBody string
+	}{
+		{"no API key", "", 2, 2, http.StatusServiceUnavailable, ErrMissingAPIKey.Error()},
+		{"every feed failed", "key", 0, 3, http.StatusServiceUnavailable, "no feed reachable"},
+		{"one feed up", "key", 1, 3, http.StatusOK, "ok (1/3 feeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEMINI_API_KEY", "")
+			t.Setenv("GOOGLE_API_KEY", tt.apiKey)
+			lastFeedRefresh.record(tt.ok, tt.all)
+			code, body := get(handleReadyz, "/readyz")
+			if code != tt.wantCode || !strings.Contains(body, tt.wantBody) {
+				t.Errorf("/readyz = %d %q, want %d containing %q", code, body, tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("no refresh yet", func(t *testing.T) {
+		t.Setenv("GOOGLE_API_KEY", "key")
+		lastFeedRefresh.mu.Lock()
+		lastFeedRefresh.at = time.Time{}
+		lastFeedRefresh.mu.Unlock()
+		if code, body := get(handleReadyz, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "no feed refresh yet") {
+			t.Errorf("/readyz = %d %q, want 503 before the first refresh", code, body)
+		}
+	})
+}
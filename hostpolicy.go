@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Restrictions on the hosts feedClient may contact, for feed lists that cannot be trusted.
+var (
+	// feedAllowedHosts lists the allowed host suffixes (FEED_ALLOWED_HOSTS); empty allows every host.
+	feedAllowedHosts []string
+	// feedNoPrivate refuses hosts that are or resolve to loopback, private or link-local addresses (-no-private).
+	feedNoPrivate = false
+)
+
+// maxFeedRedirects mirrors the default redirect limit of net/http.
+const maxFeedRedirects = 10
+
+// checkFeedURL returns an error if raw must not be fetched: a scheme other than http(s), a host
+// outside feedAllowedHosts or, with feedNoPrivate, a private IP literal. Host names are checked
+// once resolved, by checkDialedAddr.
+func checkFeedURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("refusing URL %s: scheme %q is not http(s)", redactURL(raw), u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if !hostAllowed(host, feedAllowedHosts) {
+		return fmt.Errorf("refusing URL %s: host %s is not in FEED_ALLOWED_HOSTS", redactURL(raw), host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && feedNoPrivate && !isPublicAddr(addr) {
+		return fmt.Errorf("refusing URL %s: address %s is private or loopback", redactURL(raw), addr)
+	}
+	return nil
+}
+
+// hostAllowed reports whether host equals one of suffixes or is a subdomain of one. An empty list
+// allows every host.
+func hostAllowed(host string, suffixes []string) bool {
+	if len(suffixes) == 0 {
+		return true
+	}
+	for _, s := range suffixes {
+		s = strings.TrimPrefix(s, ".")
+		if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDialedAddr is the net.Dialer Control hook of feed connections: with feedNoPrivate, it
+// refuses to connect to a non-public address, whatever name resolved to it. Checking the dialed
+// address rather than a separate lookup leaves no window for the name to resolve differently.
+func checkDialedAddr(network, address string, _ syscall.RawConn) error {
+	if !feedNoPrivate {
+		return nil
+	}
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ap.Addr()) {
+		return fmt.Errorf("refusing to connect to private or loopback address %s", ap.Addr())
+	}
+	return nil
+}
+
+// feedDialer dials every feed and article connection, through checkDialedAddr.
+var feedDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkDialedAddr}
+
+// newFeedTransport returns a copy of the default transport dialing through feedDialer.
+func newFeedTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = feedDialer.DialContext
+	return t
+}
+
+// isPublicAddr reports whether addr is neither loopback, private, link-local nor unspecified.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsLoopback() && !addr.IsPrivate() && !addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast() && !addr.IsUnspecified()
+}
+
+// checkFeedRedirect applies checkFeedURL to every redirect target, so an allowed host cannot
+// bounce the client to a refused one.
+func checkFeedRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxFeedRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	return checkFeedURL(req.URL.String())
+}
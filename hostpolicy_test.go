@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNoPrivateIsEnforcedOnTheDialedAddress(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { hits.Add(1) }))
+	t.Cleanup(srv.Close)
+	// localhost passes checkFeedURL, which no longer resolves names: only the dial can refuse it.
+	target := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	saved := feedNoPrivate
+	feedNoPrivate = true
+	t.Cleanup(func() { feedNoPrivate = saved })
+
+	if err := checkFeedURL(target); err != nil {
+		t.Fatalf("checkFeedURL(%s): %v", target, err)
+	}
+	if err := checkFeedURL(srv.URL); err == nil {
+		t.Errorf("checkFeedURL(%s) accepted a loopback IP literal", srv.URL)
+	}
+	clients := map[string]*http.Client{
+		"default transport":   {Transport: newFeedTransport()},
+		"DNS cache transport": {Transport: newDNSCachingTransport(time.Minute)},
+	}
+	for name, c := range clients {
+		resp, err := c.Get(target)
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("%s: connected to %s with -no-private", name, target)
+		} else if !strings.Contains(err.Error(), "private or loopback") {
+			t.Errorf("%s: got %v, want a private address refusal", name, err)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("the server received %d requests", n)
+	}
+
+	feedNoPrivate = false
+	resp, err := clients["DNS cache transport"].Get(target)
+	if err != nil {
+		t.Fatalf("without -no-private: %v", err)
+	}
+	resp.Body.Close()
+}
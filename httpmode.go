@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// httpMode controls how fetchRSSItems treats plain-http feed URLs.
+type httpMode string
+
+const (
+	// httpAllow fetches http URLs as-is.
+	httpAllow httpMode = "allow-http"
+	// httpUpgrade rewrites http URLs to https before fetching.
+	httpUpgrade httpMode = "upgrade"
+	// httpRefuse rejects http URLs without making a request.
+	httpRefuse httpMode = "refuse"
+)
+
+// feedHTTPMode is the plain-http policy applied by fetchRSSItems (FEED_HTTP_MODE).
+var feedHTTPMode = httpAllow
+
+func parseHTTPMode(s string) (httpMode, error) {
+	switch m := httpMode(s); m {
+	case httpAllow, httpUpgrade, httpRefuse:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid http mode %q (want %s, %s or %s)", s, httpAllow, httpUpgrade, httpRefuse)
+}
+
+// applyHTTPMode returns the URL to fetch for feedURL under mode, or an error if it must not be fetched.
+func applyHTTPMode(feedURL string, mode httpMode) (string, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" {
+		return feedURL, nil
+	}
+	switch mode {
+	case httpRefuse:
+		return "", fmt.Errorf("refusing plain http URL %s (FEED_HTTP_MODE=%s)", redactURL(feedURL), mode)
+	case httpUpgrade:
+		u.Scheme = "https"
+		return u.String(), nil
+	}
+	return feedURL, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFeedHTTPModes(t *testing.T) {
+	var plainHits, tlsHits atomic.Int32
+	feed := func(hits *atomic.Int32) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			w.Write([]byte(testFeed))
+		}
+	}
+	plain := httptest.NewServer(feed(&plainHits))
+	t.Cleanup(plain.Close)
+	secure := httptest.NewTLSServer(feed(&tlsHits))
+	t.Cleanup(secure.Close)
+
+	// Trust the test certificate; the TLS server's transport fetches plain http as well.
+	savedTransport, savedMode := feedClient.Transport, feedHTTPMode
+	feedClient.Transport = secure.Client().Transport
+	t.Cleanup(func() { feedClient.Transport, feedHTTPMode = savedTransport, savedMode })
+
+	insecureTLS := strings.Replace(secure.URL, "https://", "http://", 1)
+	tests := []struct {
+		mode      httpMode
+		url       string
+		wantErr   string
+		wantPlain int32
+		wantTLS   int32
+	}{
+		{mode: httpAllow, url: plain.URL, wantPlain: 1},
+		{mode: httpAllow, url: secure.URL, wantTLS: 1},
+		{mode: httpUpgrade, url: insecureTLS, wantTLS: 1},
+		{mode: httpUpgrade, url: secure.URL, wantTLS: 1},
+		{mode: httpRefuse, url: plain.URL, wantErr: "refusing plain http"},
+		{mode: httpRefuse, url: secure.URL, wantTLS: 1},
+	}
+	for _, tt := range tests {
+		plainHits.Store(0)
+		tlsHits.Store(0)
+		feedHTTPMode = tt.mode
+		items, err := downloadFeedItems(context.Background(), tt.url, nil, 10)
+		switch {
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s %s: got %v, want an error containing %q", tt.mode, tt.url, err, tt.wantErr)
+		case tt.wantErr == "" && (err != nil || len(items) == 0):
+			t.Errorf("%s %s: got %d items, %v; want the feed", tt.mode, tt.url, len(items), err)
+		}
+		if p, s := plainHits.Load(), tlsHits.Load(); p != tt.wantPlain || s != tt.wantTLS {
+			t.Errorf("%s %s: %d plain and %d TLS requests, want %d and %d", tt.mode, tt.url, p, s, tt.wantPlain, tt.wantTLS)
+		}
+	}
+}
+
+func TestParseHTTPMode(t *testing.T) {
+	for _, s := range []string{"allow-http", "upgrade", "refuse"} {
+		if m, err := parseHTTPMode(s); err != nil || string(m) != s {
+			t.Errorf("parseHTTPMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := parseHTTPMode("https-only"); err == nil {
+		t.Error(`parseHTTPMode("https-only") succeeded`)
+	}
+}
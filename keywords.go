@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadKeywords reads transfer keywords from path: either a JSON array of strings or one keyword
+// per line, lines starting with '#' being comments. Entries are trimmed and lowercased; blank ones
+// are dropped.
+func LoadKeywords(path string) ([]string, error) {
+	raw, err := readListFile(path, "keywords")
+	if err != nil {
+		return nil, err
+	}
+	keywords := make([]string, len(raw))
+	for i, k := range raw {
+		keywords[i] = strings.ToLower(k)
+	}
+	return keywords, nil
+}
+
+// LoadTeamNames reads team names from path, in the same formats as LoadKeywords. Names keep
+// their case, since they are shown to the model.
+func LoadTeamNames(path string) ([]string, error) {
+	return readListFile(path, "teams")
+}
+
+// readListFile reads a JSON array of strings or a file with one entry per line, trimming entries
+// and dropping blank ones and, in the line format, '#' comment lines. kind names the list in errors.
+func readListFile(path, kind string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", kind, err)
+	}
+
+	var raw []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("parsing %s %s: %w", kind, path, err)
+		}
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				raw = append(raw, line)
+			}
+		}
+	}
+
+	var entries []string
+	for _, e := range raw {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s file %s has no entries", kind, path)
+	}
+	return entries, nil
+}
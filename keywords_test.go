@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTempFile writes content to a file named name in a test directory and returns its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKeywordsFormats(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []string
+	}{
+		{"lines.txt", "# mots-clés du mercato\nTransfert\n\n  Rejoint  \n   # désactivé : quitte\nPRÊT\n", []string{"transfert", "rejoint", "prêt"}},
+		{"list.json", `  ["Mercato", " Signe ", ""]`, []string{"mercato", "signe"}},
+	}
+	for _, tt := range tests {
+		got, err := LoadKeywords(writeTempFile(t, tt.name, tt.content))
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: LoadKeywords = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	for name, content := range map[string]string{"empty.txt": "# rien\n\n", "bad.json": `["transfert"`} {
+		if _, err := LoadKeywords(writeTempFile(t, name, content)); err == nil {
+			t.Errorf("%s: LoadKeywords succeeded", name)
+		}
+	}
+}
+
+func TestFilterTransferItemsUsesLoadedKeywords(t *testing.T) {
+	keywords, err := LoadKeywords(writeTempFile(t, "kw.txt", "Prêt\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []rssItem{{Title: "Transfert : Ayuso rejoint Lidl-Trek"}, {Title: "Prêt de Martinez à Bahrain"}}
+	got, ok := filterTransferItems(items, keywords)
+	if !ok || len(got) != 1 || got[0].Title != items[1].Title {
+		t.Errorf("filterTransferItems = %+v, %v; want only the item matching the loaded keyword", got, ok)
+	}
+}
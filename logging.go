@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Log output formats accepted by -log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// setupLogging installs the default slog logger writing to w in format, at Info level or, with
+// verbose, at Debug level, which adds the per-feed fetch, retry and skip details. The standard log
+// package, still used for fatal startup errors, is routed through the same handler.
+func setupLogging(w io.Writer, format string, verbose bool) error {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if verbose {
+		opts.Level = slog.LevelDebug
+	}
+	var h slog.Handler
+	switch format {
+	case logFormatText:
+		h = slog.NewTextHandler(w, opts)
+	case logFormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (want %s or %s)", format, logFormatText, logFormatJSON)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
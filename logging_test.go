@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logTo installs the logger built by setupLogging on a buffer for the rest of the test.
+func logTo(t *testing.T, format string, verbose bool) *bytes.Buffer {
+	t.Helper()
+	saved := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(saved) })
+	var buf bytes.Buffer
+	if err := setupLogging(&buf, format, verbose); err != nil {
+		t.Fatalf("setupLogging: %v", err)
+	}
+	return &buf
+}
+
+func TestJSONLogsCarryFeedSkipFields(t *testing.T) {
+	srv := serveFeed(t, testFeed)
+	srv.Close()
+	buf := logTo(t, logFormatJSON, true)
+
+	gatherFeedItems(context.Background(), contextOptions{Refresh: true})
+	var skip map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		if r["msg"] == "skip feed" {
+			skip = r
+		}
+	}
+	if skip == nil {
+		t.Fatalf("no skip feed record in:\n%s", buf)
+	}
+	for _, key := range []string{"time", "level", "msg", "feed", "urls", "err"} {
+		if _, ok := skip[key]; !ok {
+			t.Errorf("skip feed record lacks %q: %v", key, skip)
+		}
+	}
+	if skip["feed"] != "test" || skip["level"] != "DEBUG" {
+		t.Errorf("skip feed record = %v, want feed test at DEBUG", skip)
+	}
+}
+
+func TestSetupLoggingRejectsUnknownFormat(t *testing.T) {
+	saved := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(saved) })
+	if err := setupLogging(&bytes.Buffer{}, "xml", false); err == nil {
+		t.Error("setupLogging(xml): want an error")
+	}
+}
+
+func TestVerboseGatesPerFeedLogs(t *testing.T) {
+	for _, verbose := range []bool{false, true} {
+		srv := serveFeed(t, testFeed)
+		srv.Close()
+		buf := logTo(t, logFormatText, verbose)
+
+		fetchFeedContext(context.Background(), contextOptions{Refresh: true})
+		out := buf.String()
+		for _, msg := range []string{"feed attempt failed", "skip feed"} {
+			if got := strings.Contains(out, "msg=\""+msg+"\""); got != verbose {
+				t.Errorf("verbose=%v: %q logged = %v, want %v", verbose, msg, got, verbose)
+			}
+		}
+		if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "aucun flux cyclisme accessible") {
+			t.Errorf("verbose=%v: the no-feed warning is missing:\n%s", verbose, out)
+		}
+	}
+
+	if o, err := parseArgs(t, "-verbose"); err != nil || !o.verbose {
+		t.Errorf("-verbose: verbose %v, err %v; want true", o.verbose, err)
+	}
+	if o, err := parseArgs(t); err != nil || o.verbose {
+		t.Errorf("no flag: verbose %v, err %v; want false", o.verbose, err)
+	}
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
-	"encoding/xml"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/firebase/genkit/go/plugins/googlegenai"
 )
@@ -22,247 +30,962 @@ type QuestionInput struct {
 // AnswerOutput is the typed output for the QA flow.
 type AnswerOutput struct {
 	Answer string `json:"answer"`
+	// Model is the model that produced Answer, which differs from the configured one after a fallback.
+	Model string `json:"model,omitempty"`
+	// Cached is set when the answer comes from the qaFlow answer cache; TokenUsage is then zero.
+	Cached bool `json:"cached,omitempty"`
+	TokenUsage
 }
 
 // CyclingRAGInput carries a free-form question about cycling transfers.
 type CyclingRAGInput struct {
 	Question string `json:"question"`
+	// ExtraContext holds caller-supplied snippets merged into the fetched context, labeled as user-provided.
+	ExtraContext []string `json:"extraContext,omitempty"`
+	// EventTypeFilter optionally restricts the context to one kind of transfer (signing, extension, departure, loan).
+	EventTypeFilter string `json:"eventTypeFilter,omitempty"`
+	// Verbose adds per-request feed provenance (SucceededFeeds, FailedFeeds) to the output.
+	Verbose bool `json:"verbose,omitempty"`
+	// TeamFocus restricts the answer to the roster changes of one team, returned in Arrivals and Departures.
+	TeamFocus string `json:"teamFocus,omitempty"`
+	// Refresh refetches every feed instead of reusing items cached within FEED_CACHE_TTL.
+	Refresh bool `json:"refresh,omitempty"`
+	// RequireFeeds fails the flow with ErrNoFeedsReachable instead of answering from the fallback context.
+	RequireFeeds bool `json:"requireFeeds,omitempty"`
 }
 
 // CyclingRAGOutput returns the answer and the list of sources used.
 type CyclingRAGOutput struct {
-	Answer  string   `json:"answer"`
-	Sources []string `json:"sources"`
+	Answer string `json:"answer"`
+	// Model is the model that produced Answer ("offline" in SUMMARY_MODE=offline, empty when no model was called).
+	Model string `json:"model,omitempty"`
+	// Mutations are the transfers behind Answer as structured data; empty when the model's JSON was unusable.
+	Mutations []Mutation `json:"mutations,omitempty"`
+	Sources   []string   `json:"sources"`
+	// Degraded is true when no feed item could be used and the answer relies on the fallback context.
+	Degraded bool `json:"degraded,omitempty"`
+	// ContextAsOf is the date of the newest context item, or the fetch time when no item date is known.
+	ContextAsOf time.Time `json:"contextAsOf"`
+	// Arrivals and Departures list the focused team's roster changes when TeamFocus was set.
+	Arrivals   []string `json:"arrivals,omitempty"`
+	Departures []string `json:"departures,omitempty"`
+	// SucceededFeeds and FailedFeeds list the feed URLs that did and did not contribute, when Verbose was set.
+	SucceededFeeds []string `json:"succeededFeeds,omitempty"`
+	FailedFeeds    []string `json:"failedFeeds,omitempty"`
+	// NoTransfersFound is true when no feed item matched the transfer filters; Reason says why.
+	NoTransfersFound bool   `json:"noTransfersFound,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+	// TokenUsage sums every model call of the run, critique pass included.
+	TokenUsage
 }
 
+// Machine-readable values of CyclingRAGOutput.Reason.
+const (
+	reasonNoFeedsReachable  = "no_feeds_reachable"
+	reasonNoTransferMatched = "feeds_reachable_no_transfer_matched"
+	reasonNoEventTypeMatch  = "no_item_of_requested_event_type"
+	reasonNoTeamMatch       = "no_item_for_requested_team"
+	reasonNoRecentItem      = "no_item_recent_enough"
+)
+
 const (
-	maxItemsPerFeed     = 5
-	defaultCyclingQuery = "Quelles sont les dernières mutations et transferts en cyclisme ?"
+	defaultModelName       = "googleai/gemini-2.0-flash"
+	defaultMaxItemsPerFeed = 5
+	defaultCyclingQuery    = "Quelles sont les dernières mutations et transferts en cyclisme ?"
+	demoQuestion           = "Le magazine Programmez!, donne-moi les informations principales en trois phrases."
+	demoRAGQuestion        = "Quelles sont les dernières mutations dans le cyclisme pro ?"
+
+	// Caps applied to CyclingRAGInput.ExtraContext.
+	maxExtraContextItems = 10
+	maxExtraContextChars = 500
 )
 
-var cyclingFeeds = []struct {
+// modelName is the model used by every flow, set from -model / GENKIT_MODEL at startup.
+var modelName = defaultModelName
+
+// feedConfig describes one news source and the URLs tried, in order, to fetch it.
+type feedConfig struct {
 	name string
 	urls []string
-}{
+	// maxItems overrides maxItemsPerFeed for this feed when positive.
+	maxItems int
+	// lang is the language of the feed's titles; it selects the transfer keywords (see keywordsFor).
+	lang string
+	// headers are added to every request for this feed, overriding feedUserAgent and the defaults.
+	headers map[string]string
+	// weight ranks the feed when MAX_FEEDS_PER_REQUEST keeps only some of them; higher goes first.
+	weight int
+}
+
+var cyclingFeeds = []feedConfig{
 	{
 		name: "L'Équipe (Cyclisme)",
 		urls: []string{
 			"https://dwh.lequipe.fr/api/edito/rss?path=/Cyclisme/",
 		},
+		lang: "fr",
 	},
 	{
 		name: "DirectVelo",
 		urls: []string{
 			"https://feeds.feedburner.com/ActualitsDirectvelo",
 		},
+		lang: "fr",
 	},
 }
 
+// Disclaimer prepended to degraded answers (toggled by DEGRADED_DISCLAIMER_ENABLED). When
+// DEGRADED_DISCLAIMER is unset, the localized text from fallbackMessagesByLang is used.
+var (
+	degradedDisclaimerEnabled = true
+	degradedDisclaimer        = ""
+)
+
+// feedUserAgent is the User-Agent sent with feed requests (FEED_USER_AGENT).
+var feedUserAgent = "genkit-cycling-rag/1.0 (+https://github.com/thepriben/genkit-programmez)"
+
+// maxItemsPerFeed is the number of items kept from each feed (-max-items / GENKIT_MAX_ITEMS).
+var maxItemsPerFeed = defaultMaxItemsPerFeed
+
+// feedClient is shared by every feed fetch so connections (and, optionally, DNS lookups) are reused.
+// Its timeout applies to each request (FEED_TIMEOUT); redirects go through checkFeedRedirect.
+// Feed and article downloads all go through it, so tests can swap its Transport for a stub
+// RoundTripper. Its transport honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY and dials through feedDialer.
+var feedClient = &http.Client{Timeout: 10 * time.Second, CheckRedirect: checkFeedRedirect, Transport: newFeedTransport()}
+
+// normalizeTitles collapses internal whitespace in item titles at parse time (NORMALIZE_WHITESPACE).
+var normalizeTitles = true
+
+// requireLink drops items without a usable http(s) link before building the context (REQUIRE_LINK).
+var requireLink = false
+
+// maxFeedsPerRequest caps how many feeds a single flow run processes (MAX_FEEDS_PER_REQUEST); 0 means no cap.
+var maxFeedsPerRequest = 0
+
+// transferKeywords are the lowercase title fragments that mark a transfer article; -keywords replaces them.
 var transferKeywords = []string{
 	"transfert", "transfer", "mutation", "mercato", "signe", "signature",
 	"recrut", "rejoint", "quitte", "engage", "arrive", "contrat", "renforce",
+	"prolong",
+}
+
+// transferKeywordsByLang holds the transfer keywords of feeds written in another language than
+// defaultLanguage, whose keywords are transferKeywords.
+var transferKeywordsByLang = map[string][]string{
+	"en": {
+		"transfer", "sign", "joins", "join", "leaves", "leave", "contract", "extends", "extension",
+		"recruit", "moves to", "departure", "loan",
+	},
+}
+
+// keywordsFor returns the transfer keywords for a feed in lang: its entry in transferKeywordsByLang,
+// or def for the default language and languages without an entry.
+func keywordsFor(lang string, def []string) []string {
+	if kw, ok := transferKeywordsByLang[lang]; ok && lang != defaultLanguage {
+		return kw
+	}
+	return def
 }
 
 type rssItem struct {
 	Title   string `xml:"title"`
 	Link    string `xml:"link"`
 	PubDate string `xml:"pubDate"`
+	// Excerpt is the first paragraph of the linked article, filled by enrichItems with -enrich.
+	Excerpt string `xml:"-"`
+	// Feed and Lang are the name and language of the feed the item came from, set by gatherFeedItems.
+	Feed string `xml:"-"`
+	Lang string `xml:"-"`
+	// Teams are the knownTeams named in Title, set by annotateTeams.
+	Teams []string `xml:"-"`
 }
 
 type rssFeed struct {
 	Channel struct {
+		// Links collects every channel-level <link>, including empty atom:link elements.
+		Links []string  `xml:"link"`
 		Items []rssItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 func main() {
+	opts, err := parseFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := setupLogging(os.Stderr, opts.logFormat, opts.verbose); err != nil {
+		log.Fatal(err)
+	}
+	maxItemsPerFeed = opts.maxItems
+	truncateQuestion = opts.truncateQuestion
+	cacheDir, offlineMode = opts.cacheDir, opts.offline
+	answerLanguage = opts.lang
+	maxItemAge, keepUndatedItems = opts.maxAge, opts.keepUndated
+	feedNoPrivate = opts.noPrivate
 	ctx := context.Background()
 
+	if opts.feeds != "" {
+		feeds, err := loadFeedsFile(opts.feeds)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cyclingFeeds = feeds
+		slog.Info("feeds loaded", "count", len(feeds), "path", opts.feeds)
+	}
+	if err := loadEnvConfig(); err != nil {
+		log.Fatal(err)
+	}
+	if strings.TrimSpace(opts.model) == "" {
+		log.Fatal("the model name must not be empty (-model / GENKIT_MODEL)")
+	}
+	modelName = strings.TrimSpace(opts.model)
+	slog.Info("modèle utilisé", "model", modelName)
+	generationConfig = opts.genConfig
+	if generationConfig != nil {
+		slog.Info("configuration de génération", "temperature", generationConfig.Temperature, "max_output_tokens", generationConfig.MaxOutputTokens)
+	} else {
+		slog.Info("configuration de génération", "config", "défauts du modèle")
+	}
+	if opts.keywords != "" {
+		keywords, err := LoadKeywords(opts.keywords)
+		if err != nil {
+			log.Fatal(err)
+		}
+		transferKeywords = keywords
+		slog.Info("mots-clés de transfert chargés", "count", len(keywords), "path", opts.keywords)
+	}
+	if opts.teams != "" {
+		teams, err := LoadTeamNames(opts.teams)
+		if err != nil {
+			log.Fatal(err)
+		}
+		knownTeams = teams
+		slog.Info("équipes chargées", "count", len(teams), "path", opts.teams)
+	}
+
+	if opts.dryRun {
+		if err := runDryRun(ctx, os.Stdout, CyclingRAGInput{Question: demoRAGQuestion}); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Initialize Genkit with the Google AI plugin (expects GOOGLE_API_KEY in the environment).
-	g, err := genkit.Init(ctx,
-		genkit.WithPlugins(&googlegenai.GoogleAI{}),
-	)
+	// Offline runs answer from -cache-dir and need neither the plugin nor a key.
+	var plugins []genkit.GenkitOption
+	if !offlineMode {
+		if err := CheckEnvironment(); err != nil {
+			log.Fatal(err)
+		}
+		plugins = append(plugins, genkit.WithPlugins(&googlegenai.GoogleAI{}))
+	}
+	g, err := genkit.Init(ctx, plugins...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	qaFlow := genkit.DefineFlow(g, "qaFlow",
-		func(ctx context.Context, in QuestionInput) (AnswerOutput, error) {
-			resp, err := genkit.Generate(ctx, g,
-				ai.WithModelName("googleai/gemini-2.0-flash"),
-				ai.WithPrompt(in.Question),
-			)
-			if err != nil {
-				return AnswerOutput{}, err
-			}
-			return AnswerOutput{Answer: resp.Text()}, nil
-		},
-	)
+	metricsEnabled = opts.metrics
+	enrichArticles = opts.enrich && !offlineMode
+	if opts.rerank {
+		if rerankEmbedder = lookupEmbedder(g, rerankEmbedderName); rerankEmbedder == nil {
+			slog.Warn("embedder not configured, keeping keyword filtering", "embedder", rerankEmbedderName)
+		}
+	}
+	var gen Generator = metricsGenerator{next: genkitGenerator{g: g}}
+	gen = retryGenerator{next: gen, maxAttempts: modelMaxAttempts}
+	gen = fallbackGenerator{next: gen, fallbacks: fallbackModels}
+	gen = emptyRetryGenerator{next: gen, retries: emptyResponseRetries}
+	switch {
+	case offlineMode:
+		gen = cachingGenerator{offline: true}
+	case cacheDir != "":
+		gen = cachingGenerator{next: gen}
+	}
 
-	out, err := qaFlow.Run(ctx, QuestionInput{
-		Question: "Le magazine Programmez!, donne-moi les informations principales en trois phrases.",
-	})
+	qaFlow := defineQAFlow(g, gen)
+	defineQAStreamFlow(g, gen)
+	ragFlow := defineCyclingRAGFlow(g, gen)
+	defineActivityFlow(g)
+	defineFeedItemsFlow(g)
+	defineClaimFlow(g, gen)
+
+	if opts.serve {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := serveFlows(ctx, g, opts.port); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if opts.question != "" {
+		out, err := qaFlow.Run(ctx, QuestionInput{Question: opts.question})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(out.Answer)
+		return
+	}
+	runDemo(ctx, qaFlow, ragFlow)
+}
+
+// runDemo runs qaFlow and cyclingRAG once each and prints their answers to stdout.
+func runDemo(ctx context.Context, qaFlow *core.Flow[QuestionInput, AnswerOutput, struct{}], ragFlow *core.Flow[CyclingRAGInput, CyclingRAGOutput, struct{}]) {
+	out, err := qaFlow.Run(ctx, QuestionInput{Question: demoQuestion})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Printf("Question : %s", "Le magazine Programmez!, donne-moi les informations principales en trois phrases.")
-	log.Printf("Réponse : %s", out.Answer)
-	log.Println("")
-	log.Println("---- Début RAG cyclisme ----")
+	fmt.Printf("Question : %s\n", demoQuestion)
+	fmt.Printf("Réponse : %s\n", out.Answer)
+	fmt.Println()
+	fmt.Println("---- Début RAG cyclisme ----")
 
 	// Example RAG run focused on cycling transfer news.
-	ragFlow := genkit.DefineFlow(g, "cyclingRAG",
-		func(ctx context.Context, in CyclingRAGInput) (CyclingRAGOutput, error) {
-			question := strings.TrimSpace(in.Question)
-			if question == "" {
-				question = defaultCyclingQuery
-			}
+	ragOut, err := ragFlow.Run(ctx, CyclingRAGInput{Question: demoRAGQuestion})
+	if err != nil {
+		slog.Error("cyclingRAG failed", "err", err)
+	} else if len(ragOut.Mutations) > 0 {
+		logMutations(ragOut.Mutations)
+	} else {
+		logRAGSummaries(ragOut.Answer)
+	}
+	fmt.Println("---- Fin RAG cyclisme ----")
+}
 
-			snippets, sources, err := fetchCyclingContext(ctx)
+// defineQAFlow registers qaFlow, which sends the question straight to the model.
+func defineQAFlow(g *genkit.Genkit, gen Generator) *core.Flow[QuestionInput, AnswerOutput, struct{}] {
+	return genkit.DefineFlow(g, "qaFlow",
+		func(ctx context.Context, in QuestionInput) (out AnswerOutput, err error) {
+			start := time.Now()
+			defer func() {
+				logFlowSummary(flowSummary{
+					Flow: "qaFlow", Question: in.Question, Model: respondingModel(out.Model), CacheHit: out.Cached,
+					Duration: time.Since(start), Err: err,
+				})
+			}()
+
+			question, err := validateQuestion(in.Question)
+			if err != nil {
+				return AnswerOutput{}, err
+			}
+			key := answerCacheKey(question)
+			if cached, ok := qaAnswers.get(key); ok {
+				cached.Cached, cached.TokenUsage = true, TokenUsage{}
+				return cached, nil
+			}
+			res, err := gen.Generate(ctx, GenerateRequest{Model: modelName, Prompt: question})
 			if err != nil {
-				return CyclingRAGOutput{}, err
+				return AnswerOutput{}, modelError(err)
 			}
+			out = AnswerOutput{Answer: res.Text, Model: res.Model, TokenUsage: usageOf(res)}
+			qaAnswers.put(key, out)
+			return out, nil
+		},
+	)
+}
 
-			contextBlock := strings.Join(snippets, "\n")
-			prompt := fmt.Sprintf(
-				"Tu es un assistant cyclisme.\n"+
-					"Contexte issu de flux d'actualités (mutations/transferts) :\n%s\n\n"+
-					"Question : %s\n"+
-					"Réponds en français par une liste concise de mutations : Nom — équipe actuelle -> équipe annoncée (ou rumeur). Si l'équipe n'est pas précisée, indique 'vers équipe inconnue'.",
-				contextBlock, question,
-			)
-
-			resp, err := genkit.Generate(ctx, g,
-				ai.WithModelName("googleai/gemini-2.0-flash"),
-				ai.WithPrompt(prompt),
-			)
+// defineQAStreamFlow registers qaFlowStream, the streaming variant of qaFlow: answer text is
+// emitted chunk by chunk and the full answer is returned at the end.
+func defineQAStreamFlow(g *genkit.Genkit, gen Generator) *core.Flow[QuestionInput, AnswerOutput, string] {
+	return genkit.DefineStreamingFlow(g, "qaFlowStream",
+		func(ctx context.Context, in QuestionInput, stream core.StreamCallback[string]) (out AnswerOutput, err error) {
+			start := time.Now()
+			defer func() {
+				logFlowSummary(flowSummary{Flow: "qaFlowStream", Question: in.Question, Model: respondingModel(out.Model), Duration: time.Since(start), Err: err})
+			}()
+
+			question, err := validateQuestion(in.Question)
+			if err != nil {
+				return AnswerOutput{}, err
+			}
+			res, err := gen.Generate(ctx, GenerateRequest{Model: modelName, Prompt: question, Stream: stream})
 			if err != nil {
-				return CyclingRAGOutput{}, err
+				return AnswerOutput{}, modelError(err)
 			}
-
-			return CyclingRAGOutput{
-				Answer:  resp.Text(),
-				Sources: sources,
-			}, nil
+			return AnswerOutput{Answer: res.Text, Model: res.Model, TokenUsage: usageOf(res)}, nil
 		},
 	)
+}
 
-	ragOut, err := ragFlow.Run(ctx, CyclingRAGInput{
-		Question: "Quelles sont les dernières mutations dans le cyclisme pro ?",
+// defineCyclingRAGFlow registers cyclingRAG, which answers from recent cycling transfer news.
+func defineCyclingRAGFlow(g *genkit.Genkit, gen Generator) *core.Flow[CyclingRAGInput, CyclingRAGOutput, struct{}] {
+	return defineFeedRAGFlow(g, gen, "cyclingRAG", cyclingRAGConfig)
+}
+
+// cyclingContext gathers the snippets and sources cyclingRAG answers question from: the feed items
+// selected by in's filters, followed by the caller's extra context.
+func cyclingContext(ctx context.Context, cfg FeedRAGConfig, in CyclingRAGInput, question string, evType eventType) ([]string, []string, feedStats, error) {
+	snippets, sources, stats, err := fetchFeedContext(ctx, contextOptions{
+		Feeds:         cfg.Feeds,
+		Keywords:      cfg.Keywords,
+		EventType:     evType,
+		Team:          strings.TrimSpace(in.TeamFocus),
+		Lang:          messagesLanguage(question),
+		Refresh:       in.Refresh,
+		FailOnNoFeeds: in.RequireFeeds,
+		Question:      question,
 	})
 	if err != nil {
-		log.Printf("RAG cycling error: %v", err)
-	} else {
-		logRAGSummaries(ragOut.Answer)
+		return nil, nil, stats, err
 	}
-	log.Println("---- Fin RAG cyclisme ----")
+	return append(snippets, extraContextSnippets(in.ExtraContext)...), sources, stats, nil
 }
 
-func fetchCyclingContext(ctx context.Context) ([]string, []string, error) {
-	var snippets []string
-	var sources []string
+// cyclingPrompt is the prompt cyclingRAG sends to the model for contextBlock and question.
+func cyclingPrompt(cfg FeedRAGConfig, contextBlock, question string) string {
+	return fmt.Sprintf(cfg.PromptTemplate, contextBlock, question)
+}
+
+// feedStats summarizes what a fetchFeedContext call gathered.
+type feedStats struct {
+	FeedsOK    int
+	FeedsTotal int
+	// FeedsCached counts the feeds among FeedsOK served from itemCache.
+	FeedsCached int
+	Items       int
+	// Degraded is set when no item was gathered and the fallback snippet was used instead.
+	Degraded bool
+	// SucceededURLs are the feed URLs that returned items; FailedURLs were tried and errored or returned nothing.
+	SucceededURLs []string
+	FailedURLs    []string
+	// ContextAsOf is the newest parsed item date or, when none parses, the latest download of the
+	// feeds that answered (which may predate the run when served from itemCache).
+	ContextAsOf time.Time
+	// TransfersMatched is set when at least one item matched a transfer keyword.
+	TransfersMatched bool
+	// Reason is one of the reason* codes when no transfer item was found, empty otherwise.
+	Reason string
+	// ContextItems are the feed items behind the context snippets, in snippet order.
+	ContextItems []rssItem
+}
+
+// contextOptions carries the per-request knobs of fetchFeedContext.
+type contextOptions struct {
+	// Feeds and Keywords default to cyclingFeeds and transferKeywords when nil.
+	Feeds    []feedConfig
+	Keywords []string
+	// EventType keeps only items of that transfer kind when non-empty.
+	EventType eventType
+	// Team keeps only items mentioning that team (accent-insensitive) when non-empty.
+	Team string
+	// Lang selects the language of the fallback snippets.
+	Lang string
+	// Refresh bypasses the feed cache and replaces its entries.
+	Refresh bool
+	// FailOnNoFeeds returns ErrNoFeedsReachable when every feed fails, instead of the fallback snippet.
+	FailOnNoFeeds bool
+	// Question is what items are re-ranked against when rerankEmbedder is set.
+	Question string
+}
+
+// gatherFeedItems fetches opts.Feeds, keeps the items matching opts.Keywords and merges them in
+// feedMergeOrder, or re-ranks all of them against opts.Question when rerankEmbedder is set.
+// It also returns the URLs of the feeds that answered.
+func gatherFeedItems(ctx context.Context, opts contextOptions) ([]rssItem, []string, feedStats) {
+	var stats feedStats
+	var perFeed, perFeedAll [][]rssItem
+	var feedURLs []string
+
+	feeds := opts.Feeds
+	if feeds == nil {
+		feeds = cyclingFeeds
+	}
+	keywords := opts.Keywords
+	if keywords == nil {
+		keywords = transferKeywords
+	}
+	if feedShuffle {
+		feeds = shuffleFeeds(feeds, feedShuffleSeed)
+	}
+	if maxFeedsPerRequest > 0 && len(feeds) > maxFeedsPerRequest {
+		feeds = byWeight(feeds)
+		var skipped []string
+		for _, feed := range feeds[maxFeedsPerRequest:] {
+			skipped = append(skipped, feed.name)
+		}
+		slog.Warn("flux ignorés (MAX_FEEDS_PER_REQUEST)", "count", len(skipped), "max", maxFeedsPerRequest, "feeds", strings.Join(skipped, ", "))
+		feeds = feeds[:maxFeedsPerRequest]
+	}
 
-	for _, feed := range cyclingFeeds {
-		items, srcURL, err := fetchFirstWorkingFeed(ctx, feed.urls, maxItemsPerFeed)
+	// Fetch every feed concurrently; each goroutine writes only its own slot, so results
+	// keep the feed order without further locking.
+	type fetchResult struct {
+		items   []rssItem
+		srcURL  string
+		fetched time.Time
+		cached  bool
+		err     error
+	}
+	results := make([]fetchResult, len(feeds))
+	var wg sync.WaitGroup
+	for i, feed := range feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit := maxItemsPerFeed
+			if feed.maxItems > 0 {
+				limit = feed.maxItems
+			}
+			items, srcURL, fetched, cached, err := fetchFirstWorkingFeed(ctx, feed.urls, feed.headers, limit, opts.Refresh)
+			results[i] = fetchResult{items: items, srcURL: srcURL, fetched: fetched, cached: cached, err: err}
+		}()
+	}
+	wg.Wait()
+
+	stats.FeedsTotal = len(feeds)
+	var lastFetch time.Time // latest download among the feeds that answered, cached ones included
+	for i, feed := range feeds {
+		items, srcURL, err := results[i].items, results[i].srcURL, results[i].err
 		if err != nil {
-			log.Printf("skip feed %s: %v", feed.name, err)
+			slog.Debug("skip feed", "feed", feed.name, "urls", redactURLs(feed.urls), "err", err)
+			stats.FailedURLs = append(stats.FailedURLs, feed.urls...)
 			continue
 		}
-		for _, it := range filterTransferItems(items) {
-			date := it.PubDate
-			if date == "" {
-				date = "date inconnue"
-			}
-			snippets = append(snippets, fmt.Sprintf("- %s (%s)", it.Title, date))
-			if it.Link != "" {
-				sources = append(sources, it.Link)
+		stats.FeedsOK++
+		if results[i].cached {
+			stats.FeedsCached++
+		}
+		if results[i].fetched.After(lastFetch) {
+			lastFetch = results[i].fetched
+		}
+		// URLs are tried in order, so every URL before the working one failed.
+		for _, u := range feed.urls {
+			if u == srcURL {
+				break
 			}
+			stats.FailedURLs = append(stats.FailedURLs, u)
+		}
+		stats.SucceededURLs = append(stats.SucceededURLs, srcURL)
+		slog.Debug("feed fetched", "feed", feed.name, "url", redactURL(srcURL), "items", len(items))
+		if requireLink {
+			items = dropLinklessItems(items)
 		}
+		for i := range items {
+			items[i].Feed, items[i].Lang = feed.name, feed.lang
+		}
+		transfers, ok := filterTransferItems(items, keywordsFor(feed.lang, keywords))
+		stats.TransfersMatched = stats.TransfersMatched || ok
+		perFeed = append(perFeed, filterTeamItems(filterEventType(transfers, opts.EventType), opts.Team))
+		perFeedAll = append(perFeedAll, filterTeamItems(filterEventType(items, opts.EventType), opts.Team))
 		if srcURL != "" {
-			sources = append(sources, srcURL)
+			feedURLs = append(feedURLs, srcURL)
+		}
+	}
+
+	lastFeedRefresh.record(stats.FeedsOK, stats.FeedsTotal)
+
+	items := mergeFeedItems(perFeed, feedMergeOrder)
+	if rerankEmbedder != nil {
+		items = rerankOrFallback(ctx, opts.Question, mergeFeedItems(perFeedAll, feedMergeOrder), items)
+	}
+	stats.Items = len(items)
+	if lastFetch.IsZero() {
+		lastFetch = time.Now()
+	}
+	stats.ContextAsOf = newestItemDate(items, lastFetch)
+	return items, feedURLs, stats
+}
+
+// selectFeedItems runs the fetch and filter stages shared by fetchFeedContext and cyclingFeedItems:
+// gathering, the -max-age filter and deduplication. It also returns how many items were too old.
+func selectFeedItems(ctx context.Context, opts contextOptions) ([]rssItem, []string, feedStats, int, error) {
+	items, feedURLs, stats := gatherFeedItems(ctx, opts)
+	if stats.FeedsOK == 0 && opts.FailOnNoFeeds {
+		return nil, nil, stats, 0, ErrNoFeedsReachable
+	}
+	var tooOld int
+	if maxItemAge > 0 {
+		items, tooOld = filterByAge(items, maxItemAge, keepUndatedItems, time.Now())
+		if tooOld > 0 {
+			slog.Info("articles trop anciens ignorés (-max-age)", "max_age", maxItemAge, "dropped", tooOld)
 		}
 	}
+	return dedupeItems(items), feedURLs, stats, tooOld, nil
+}
+
+// fetchFeedContext turns the items gathered from opts.Feeds into context snippets and source URLs,
+// substituting a fallback snippet when nothing usable was found.
+func fetchFeedContext(ctx context.Context, opts contextOptions) ([]string, []string, feedStats, error) {
+	var snippets []string
+	var sources []string
 
+	items, feedURLs, stats, tooOld, err := selectFeedItems(ctx, opts)
+	if err != nil {
+		return nil, nil, stats, err
+	}
+	items = annotateTeams(items, knownTeams)
+	// Enrich after budgeting, so no page is fetched for an item the budget leaves out.
+	items, omitted := fitContextBudget(items, maxContextChars)
+	if enrichArticles {
+		items = dropOverflowingExcerpts(enrichItems(ctx, items), maxContextChars)
+	}
+	stats.ContextItems = items
+	for _, it := range items {
+		snippets = append(snippets, contextSnippet(it))
+		if link, ok := sanitizeSourceURL(it.Link); ok {
+			sources = append(sources, link)
+		}
+	}
+	if omitted > 0 {
+		slog.Info("contexte tronqué (MAX_CONTEXT_CHARS)", "max_chars", maxContextChars, "omitted", omitted)
+		snippets = append(snippets, fmt.Sprintf(messagesFor(opts.Lang).Truncated, omitted))
+	}
+	sources = dedupeStrings(append(sources, feedURLs...))
+
+	switch {
+	case stats.FeedsOK == 0:
+		slog.Warn("aucun flux cyclisme accessible, usage d'un contexte de secours")
+		snippets = append(snippets, messagesFor(opts.Lang).NoFeeds)
+		stats.Degraded = true
+		stats.Reason = reasonNoFeedsReachable
+	case !stats.TransfersMatched:
+		stats.Reason = reasonNoTransferMatched
+	case len(snippets) == 0 && tooOld > 0:
+		stats.Reason = reasonNoRecentItem
+	case len(snippets) == 0 && opts.Team != "":
+		stats.Reason = reasonNoTeamMatch
+	case len(snippets) == 0:
+		stats.Reason = reasonNoEventTypeMatch
+	}
 	if len(snippets) == 0 {
-		log.Printf("warning: aucun flux cyclisme accessible, usage d'un contexte de secours.")
-		snippets = append(snippets, "- Aucun flux cyclisme accessible pour le moment. Réponds de façon générale et prudente sur les transferts récents.")
+		snippets = append(snippets, messagesFor(opts.Lang).NoMatch)
+	}
+
+	return snippets, sources, stats, nil
+}
+
+// contextSnippet formats it as a context line: "- Title (date)", followed by the teams it names
+// and by ": excerpt" when enriched.
+func contextSnippet(it rssItem) string {
+	date := it.PubDate
+	if date == "" {
+		date = "date inconnue"
 	}
+	line := fmt.Sprintf("- %s (%s)", truncateText(it.Title, maxTitleChars, truncationMarker), date)
+	if len(it.Teams) > 0 {
+		line += " [équipes : " + strings.Join(it.Teams, ", ") + "]"
+	}
+	if it.Excerpt != "" {
+		line += " : " + it.Excerpt
+	}
+	return line
+}
 
-	return snippets, sources, nil
+// extraContextSnippets turns caller-supplied context into labeled snippet lines, dropping blanks and
+// enforcing maxExtraContextItems and maxExtraContextChars.
+func extraContextSnippets(extra []string) []string {
+	var lines []string
+	for _, e := range extra {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if len(lines) == maxExtraContextItems {
+			slog.Warn("contexte utilisateur limité", "max_items", maxExtraContextItems)
+			break
+		}
+		e = truncateText(e, maxExtraContextChars, truncationMarker)
+		lines = append(lines, fmt.Sprintf("- [contexte fourni par l'utilisateur, non vérifié] %s", e))
+	}
+	return lines
 }
 
+// logRAGSummaries prints the cleaned answer lines of cyclingRAG to stdout as a bullet list.
 func logRAGSummaries(answer string) {
-	log.Println("Mutations détectées :")
-	lines := strings.Split(answer, "\n")
-	for _, l := range lines {
+	fmt.Println("Mutations détectées :")
+	for _, l := range summaryLines(answer) {
+		fmt.Printf("- %s\n", l)
+	}
+}
+
+// minSummaryLineChars is the shortest answer line summaryLines keeps.
+const minSummaryLineChars = 4
+
+// riderSeparators end the rider name at the start of a summary line ("Rider : A → B", "Rider (Team)").
+var riderSeparators = []string{":", " (", " - ", " – ", " → ", " ->", ","}
+
+// summaryLines splits answer into bullet texts: list markers are stripped, header lines
+// ("Mutations :", "## …", "**…**") and lines shorter than minSummaryLineChars are dropped, and only
+// the first line per rider name (case-insensitive) is kept.
+func summaryLines(answer string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, l := range strings.Split(answer, "\n") {
 		trimmed := strings.TrimSpace(l)
+		if isSummaryHeader(trimmed) {
+			continue
+		}
 		trimmed = strings.TrimPrefix(trimmed, "*")
 		trimmed = strings.TrimPrefix(trimmed, "-")
 		trimmed = strings.TrimSpace(trimmed)
-		if trimmed != "" {
-			log.Printf("- %s", trimmed)
+		if len([]rune(trimmed)) < minSummaryLineChars {
+			continue
+		}
+		key := normalizeTitle(summaryRider(trimmed))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, trimmed)
+	}
+	return out
+}
+
+// isSummaryHeader reports whether line is a heading rather than a mutation: a Markdown title, a
+// fully bold line or a line ending with a colon.
+func isSummaryHeader(line string) bool {
+	switch {
+	case strings.HasPrefix(line, "#"):
+		return true
+	case len(line) > 4 && strings.HasPrefix(line, "**") && strings.HasSuffix(line, "**"):
+		return true
+	}
+	return strings.HasSuffix(strings.TrimRight(line, "* "), ":")
+}
+
+// summaryRider returns the rider name at the start of a summary line, or the whole line when no
+// separator follows it.
+func summaryRider(line string) string {
+	line = strings.ReplaceAll(line, "**", "")
+	end := len(line)
+	for _, sep := range riderSeparators {
+		if i := strings.Index(line, sep); i > 0 && i < end {
+			end = i
 		}
 	}
+	return line[:end]
 }
 
-func filterTransferItems(items []rssItem) []rssItem {
+// filterTransferItems keeps the items whose title matches keywords under transferMatchMode and reports
+// whether any did. When none match, the original list is returned so the feed still contributes context.
+func filterTransferItems(items []rssItem, keywords []string) ([]rssItem, bool) {
 	var filtered []rssItem
 	for _, it := range items {
-		titleLower := strings.ToLower(it.Title)
-		for _, kw := range transferKeywords {
-			if strings.Contains(titleLower, kw) {
-				filtered = append(filtered, it)
-				break
-			}
+		if matchesKeywords(strings.ToLower(it.Title), keywords, transferMatchMode, transferMatchThreshold) {
+			filtered = append(filtered, it)
 		}
 	}
 	// If nothing matched, fall back to the original list to avoid empty context per feed.
 	if len(filtered) == 0 {
-		return items
+		return items, false
+	}
+	return filtered, true
+}
+
+// newestItemDate returns the most recent parseable PubDate among items, or fallback when none parses.
+func newestItemDate(items []rssItem, fallback time.Time) time.Time {
+	var newest time.Time
+	for _, it := range items {
+		if t, ok := parsePubDate(it.PubDate); ok && t.After(newest) {
+			newest = t
+		}
+	}
+	if newest.IsZero() {
+		return fallback
+	}
+	return newest
+}
+
+// dropLinklessItems keeps the items whose Link is an absolute http(s) URL.
+func dropLinklessItems(items []rssItem) []rssItem {
+	var kept []rssItem
+	for _, it := range items {
+		u, err := url.Parse(strings.TrimSpace(it.Link))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		kept = append(kept, it)
+	}
+	if dropped := len(items) - len(kept); dropped > 0 {
+		slog.Info("articles sans lien exploitable ignorés (REQUIRE_LINK)", "dropped", dropped)
 	}
-	return filtered
+	return kept
+}
+
+// isTransferItem reports whether the title of it counts as transfer news under transferMatchMode,
+// using the keywords of its feed's language (see keywordsFor).
+func isTransferItem(it rssItem) bool {
+	return matchesKeywords(strings.ToLower(it.Title), keywordsFor(it.Lang, transferKeywords), transferMatchMode, transferMatchThreshold)
+}
+
+// hasTransferKeyword reports whether s contains any transfer keyword of lang, regardless of the match mode.
+func hasTransferKeyword(s, lang string) bool {
+	return matchesKeywords(strings.ToLower(s), keywordsFor(lang, transferKeywords), matchAny, 0)
 }
 
-func fetchFirstWorkingFeed(ctx context.Context, urls []string, limit int) ([]rssItem, string, error) {
+// fetchFirstWorkingFeed returns the items of the first URL of urls that yields any, that URL, when
+// the items were downloaded, and whether they came from itemCache.
+func fetchFirstWorkingFeed(ctx context.Context, urls []string, headers map[string]string, limit int, refresh bool) ([]rssItem, string, time.Time, bool, error) {
 	for _, feedURL := range urls {
-		items, err := fetchRSSItems(ctx, feedURL, limit)
+		if !feedBreaker.allow(feedURL) {
+			slog.Debug("feed URL skipped, circuit open", "url", redactURL(feedURL))
+			continue
+		}
+		items, fetched, cached, err := itemCache.fetchCachedItems(ctx, feedURL, headers, limit, refresh)
+		if cached {
+			// The last fetch recorded for /feeds is the last download, not the last cache hit.
+			feedBreaker.release(feedURL)
+		} else {
+			feedBreaker.record(feedURL, len(items), err)
+		}
 		if err == nil && len(items) > 0 {
-			return items, feedURL, nil
+			return items, feedURL, fetched, cached, nil
 		}
 		if err != nil {
-			log.Printf("feed attempt failed (%s): %v", feedURL, err)
+			slog.Debug("feed attempt failed", "url", redactURL(feedURL), "err", err)
 		}
 	}
-	return nil, "", fmt.Errorf("no working URL among %v", urls)
+	return nil, "", time.Time{}, false, fmt.Errorf("no working URL among %v", redactURLs(urls))
+}
+
+// fetchRSSItems downloads and parses feedURL, keeping at most limit items, and records the fetch in
+// the feed metrics. headers are set last, so they override the User-Agent and Accept-Encoding defaults.
+func fetchRSSItems(ctx context.Context, feedURL string, headers map[string]string, limit int) ([]rssItem, error) {
+	if offlineMode {
+		return loadFeedFromDisk(feedURL, limit)
+	}
+	items, err := downloadFeedItems(ctx, feedURL, headers, limit)
+	metrics.recordFeedFetch(feedURL, len(items), err)
+	if err == nil && cacheDir != "" {
+		saveFeedToDisk(feedURL, items)
+	}
+	return items, err
 }
 
-func fetchRSSItems(ctx context.Context, feedURL string, limit int) ([]rssItem, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+func downloadFeedItems(ctx context.Context, feedURL string, headers map[string]string, limit int) ([]rssItem, error) {
+	target, err := applyHTTPMode(feedURL, feedHTTPMode)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", "genkit-cycling-rag/1.0 (+https://github.com/thepriben/genkit-programmez)")
+	if err := checkFeedURL(target); err != nil {
+		return nil, err
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	req.Header.Set("User-Agent", feedUserAgent)
+	// Asking explicitly turns off the transport's transparent decompression, so readFeedBody handles it.
+	req.Header.Set("Accept-Encoding", "gzip")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	validators, known := conditionalStore.get(feedURL)
+	if known {
+		setConditionalHeaders(req, validators)
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	resp, err := doWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		if !known {
+			return nil, fmt.Errorf("unexpected 304 Not Modified without cached items")
+		}
+		return slices.Clone(validators.items), nil
 	}
 
-	var feed rssFeed
-	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+	body, err := readFeedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := bodyHashes.lookup(feedURL, body, limit); ok {
+		slog.Debug("feed body unchanged, reusing parsed items", "url", redactURL(feedURL))
+		conditionalStore.put(feedURL, resp.Header, items)
+		return items, nil
+	}
+	items, channelLinks, err := parseFeedDocument(body, resp.Header.Get("Content-Type"))
+	if err != nil {
 		return nil, err
 	}
 
-	items := feed.Channel.Items
+	recordFingerprint(feedURL, fingerprintItems(items))
 	if len(items) > limit {
 		items = items[:limit]
 	}
+	// resp.Request is the last request after redirects; custom RoundTrippers may leave it unset.
+	fetchedURL := req.URL
+	if resp.Request != nil {
+		fetchedURL = resp.Request.URL
+	}
+	base := feedBaseURL(fetchedURL, channelLinks)
+	for i := range items {
+		items[i].Link = resolveLink(base, items[i].Link)
+		items[i].Title = plainTitle(items[i].Title)
+		if normalizeTitles {
+			items[i].Title = collapseWhitespace(items[i].Title)
+		}
+	}
+	conditionalStore.put(feedURL, resp.Header, items)
+	bodyHashes.put(feedURL, body, limit, items)
 	return items, nil
 }
+
+// maxFeedBytes bounds the size of a feed document, after decompression.
+const maxFeedBytes = 10 << 20
+
+// readFeedBody reads resp's body, gunzipping it when the server sent Content-Encoding: gzip.
+// Bodies larger than maxFeedBytes once decoded are rejected.
+func readFeedBody(resp *http.Response) ([]byte, error) {
+	switch enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+		return readAtMost(resp.Body, maxFeedBytes)
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing feed: %w", err)
+		}
+		defer zr.Close()
+		return readAtMost(zr, maxFeedBytes)
+	default:
+		return nil, fmt.Errorf("unsupported feed Content-Encoding %q", enc)
+	}
+}
+
+// readAtMost reads r to the end, failing once more than limit bytes have been read.
+func readAtMost(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("feed body larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// feedBaseURL returns the URL relative item links are resolved against: the first channel link
+// (itself resolved against the fetch URL), or the fetch URL when the channel has none.
+func feedBaseURL(fetched *url.URL, channelLinks []string) *url.URL {
+	for _, l := range channelLinks {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if u, err := url.Parse(l); err == nil {
+			return fetched.ResolveReference(u)
+		}
+	}
+	return fetched
+}
+
+// collapseWhitespace replaces every run of whitespace (including newlines and tabs) with a single space and trims the ends.
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// resolveLink makes link absolute against base. Empty or unparseable links are returned unchanged.
+func resolveLink(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.IsAbs() {
+		return link
+	}
+	return base.ResolveReference(u).String()
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// gzipped returns data compressed with gzip.
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownloadFeedItemsContentEncoding(t *testing.T) {
+	bomb := gzipped(t, append([]byte("<rss><channel><title>"), bytes.Repeat([]byte(" "), maxFeedBytes)...))
+	bodies := map[string]struct {
+		encoding string
+		body     []byte
+	}{
+		"/gzip":     {"gzip", gzipped(t, []byte(testFeed))},
+		"/identity": {"", []byte(testFeed)},
+		"/brotli":   {"br", []byte("not really brotli")},
+		"/bomb":     {"gzip", bomb},
+	}
+	var acceptEncoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		b := bodies[r.URL.Path]
+		if b.encoding != "" {
+			w.Header().Set("Content-Encoding", b.encoding)
+		}
+		w.Write(b.body)
+	}))
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+
+	for _, path := range []string{"/gzip", "/identity"} {
+		items, err := downloadFeedItems(ctx, srv.URL+path, nil, 10)
+		if err != nil || len(items) != 2 || items[0].Title != "Transfert : Pogačar rejoint Cofidis" {
+			t.Errorf("%s: got %+v, %v; want the two feed items", path, items, err)
+		}
+	}
+	if acceptEncoding != "gzip" {
+		t.Errorf("Accept-Encoding = %q, want gzip", acceptEncoding)
+	}
+	for path, want := range map[string]string{"/brotli": `unsupported feed Content-Encoding "br"`, "/bomb": "larger than"} {
+		if _, err := downloadFeedItems(ctx, srv.URL+path, nil, 10); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want an error containing %q", path, err, want)
+		}
+	}
+}
+
+func TestGatherFeedItemsContextAsOf(t *testing.T) {
+	serveFeed(t, `<rss><channel>
+<item><title>Transfert : Pogačar rejoint Cofidis</title><pubDate>Mon, 02 Jun 2025 10:00:00 +0200</pubDate></item>
+<item><title>Transfert : Evenepoel rejoint Red Bull</title><pubDate>Tue, 03 Jun 2025 08:30:00 +0200</pubDate></item>
+<item><title>Transfert : Ayuso quitte UAE</title></item>
+</channel></rss>`)
+	_, _, stats := gatherFeedItems(context.Background(), contextOptions{Refresh: true})
+	if want := time.Date(2025, 6, 3, 8, 30, 0, 0, time.FixedZone("", 2*3600)); !stats.ContextAsOf.Equal(want) {
+		t.Errorf("ContextAsOf = %v, want the newest item date %v", stats.ContextAsOf, want)
+	}
+
+	// Without any item date it is the download time, which a cache hit does not move forward.
+	serveFeed(t, `<rss><channel><item><title>Transfert : Ayuso quitte UAE</title></item></channel></rss>`)
+	t.Cleanup(func() { itemCache.invalidate("") })
+	before := time.Now()
+	_, _, fetched := gatherFeedItems(context.Background(), contextOptions{Refresh: true})
+	if fetched.ContextAsOf.Before(before) || fetched.ContextAsOf.After(time.Now()) {
+		t.Errorf("ContextAsOf = %v, want the fetch time, after %v", fetched.ContextAsOf, before)
+	}
+	time.Sleep(10 * time.Millisecond)
+	_, _, cached := gatherFeedItems(context.Background(), contextOptions{})
+	if cached.FeedsCached != 1 || !cached.ContextAsOf.Equal(fetched.ContextAsOf) {
+		t.Errorf("from the cache: ContextAsOf = %v (%d cached feeds), want the original fetch time %v", cached.ContextAsOf, cached.FeedsCached, fetched.ContextAsOf)
+	}
+}
+
+func TestExtraContextSnippetsCaps(t *testing.T) {
+	warnings := captureLogs(t, "contexte utilisateur limité")
+	const label = "- [contexte fourni par l'utilisateur, non vérifié] "
+
+	extra := []string{"  ", strings.Repeat("Pogačar rejoint Cofidis. ", 40)}
+	for range maxExtraContextItems {
+		extra = append(extra, "Ayuso quitte UAE")
+	}
+	got := extraContextSnippets(extra)
+	if len(got) != maxExtraContextItems {
+		t.Fatalf("kept %d snippets, want %d (blank one dropped, the rest capped)", len(got), maxExtraContextItems)
+	}
+	first := strings.TrimPrefix(got[0], label)
+	if n := utf8.RuneCountInString(first); n > maxExtraContextChars || !strings.HasSuffix(first, truncationMarker) {
+		t.Errorf("long snippet kept %d characters, want at most %d ending with the truncation marker", n, maxExtraContextChars)
+	}
+	if got[1] != label+"Ayuso quitte UAE" {
+		t.Errorf("second snippet = %q", got[1])
+	}
+	if n := len(warnings()); n != 1 {
+		t.Errorf("logged %d cap warnings, want 1", n)
+	}
+}
+
+func TestRequireLinkDropsLinklessItems(t *testing.T) {
+	serveFeed(t, `<rss><channel>
+<item><title>Transfert : Pogačar rejoint Cofidis</title><link>https://example.com/a</link></item>
+<item><title>Transfert : Ayuso quitte UAE</title></item>
+<item><title>Transfert : Evenepoel rejoint Red Bull</title><link>javascript:alert(1)</link></item>
+<item><title>Transfert : Pedersen prolonge</title><link>mailto:mercato@example.com</link></item>
+</channel></rss>`)
+	saved := requireLink
+	t.Cleanup(func() { requireLink = saved })
+
+	for _, tt := range []struct {
+		require bool
+		want    int
+	}{{false, 4}, {true, 1}} {
+		requireLink = tt.require
+		items, _, _ := gatherFeedItems(context.Background(), contextOptions{Refresh: true})
+		if len(items) != tt.want {
+			t.Errorf("REQUIRE_LINK=%v kept %d items, want %d: %+v", tt.require, len(items), tt.want, items)
+		}
+	}
+}
+
+func TestGatherFeedItemsPerFeedItemLimit(t *testing.T) {
+	const three = `<rss><channel>
+<item><title>Transfert : Pogačar rejoint Cofidis</title></item>
+<item><title>Transfert : Ayuso quitte UAE</title></item>
+<item><title>Transfert : Evenepoel rejoint Red Bull</title></item>
+</channel></rss>`
+	serveFeeds(t, []feedConfig{
+		{name: "capped", urls: []string{"/capped"}, lang: "fr", maxItems: 1},
+		{name: "default", urls: []string{"/default"}, lang: "fr"},
+	}, map[string]string{"/capped": three, "/default": strings.ReplaceAll(three, "Transfert :", "Mercato : transfert de")})
+	saved := maxItemsPerFeed
+	maxItemsPerFeed = 2
+	t.Cleanup(func() { maxItemsPerFeed = saved })
+
+	items, _, _ := gatherFeedItems(context.Background(), contextOptions{Refresh: true})
+	perFeed := map[string]int{}
+	for _, it := range items {
+		perFeed[it.Feed]++
+	}
+	if perFeed["capped"] != 1 || perFeed["default"] != 2 {
+		t.Errorf("items per feed = %v, want capped:1 (its maxItems) and default:2 (maxItemsPerFeed)", perFeed)
+	}
+}
+
+func TestDownloadFeedItemsResolvesRelativeLinks(t *testing.T) {
+	items := fetchFixture(t, "application/rss+xml", `<rss><channel><link>https://www.example.com/velo/</link>
+<item><title>a</title><link>article/1</link></item>
+<item><title>b</title><link> /mercato/2 </link></item>
+<item><title>c</title><link>https://other.example.org/3</link></item>
+<item><title>d</title></item>
+</channel></rss>`)
+	want := []string{"https://www.example.com/velo/article/1", "https://www.example.com/mercato/2", "https://other.example.org/3", ""}
+	if got := links(items); !slices.Equal(got, want) {
+		t.Errorf("links = %q, want %q", got, want)
+	}
+
+	// Without a channel link, items resolve against the URL the feed was fetched from.
+	items = fetchFixture(t, "application/rss+xml", `<rss><channel><item><title>a</title><link>article/1</link></item></channel></rss>`)
+	if got := links(items)[0]; !strings.HasPrefix(got, "http://127.0.0.1:") || !strings.HasSuffix(got, "/article/1") {
+		t.Errorf("link = %q, want it resolved against the test server", got)
+	}
+}
+
+func links(items []rssItem) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.Link
+	}
+	return out
+}
+
+func TestDownloadFeedItemsCollapsesTitleWhitespace(t *testing.T) {
+	const feed = "<rss><channel><item><title>  Transfert :\n\tPogačar\r\n  rejoint   Cofidis \n</title></item></channel></rss>"
+	saved := normalizeTitles
+	t.Cleanup(func() { normalizeTitles = saved })
+
+	normalizeTitles = true
+	if got := fetchFixture(t, "application/rss+xml", feed)[0].Title; got != "Transfert : Pogačar rejoint Cofidis" {
+		t.Errorf("title = %q, want a single clean line", got)
+	}
+	normalizeTitles = false
+	if got := fetchFixture(t, "application/rss+xml", feed)[0].Title; !strings.Contains(got, "\n") {
+		t.Errorf("NORMALIZE_WHITESPACE=false: title = %q, want the newlines kept", got)
+	}
+}
+
+func TestFeedRequestHeaders(t *testing.T) {
+	saved := feedUserAgent
+	feedUserAgent = "test-agent/1.0"
+	t.Cleanup(func() { feedUserAgent = saved })
+
+	got := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Clone()
+		w.Write([]byte(testFeed))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		path               string
+		headers            map[string]string
+		wantUA, wantAccept string
+	}{
+		{"/plain", nil, "test-agent/1.0", ""},
+		{"/custom", map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "application/atom+xml"}, "Mozilla/5.0", "application/atom+xml"},
+	}
+	for _, tt := range tests {
+		if _, err := fetchRSSItems(context.Background(), srv.URL+tt.path, tt.headers, 5); err != nil {
+			t.Fatalf("fetching %s: %v", tt.path, err)
+		}
+		h := <-got
+		if h.Get("User-Agent") != tt.wantUA || h.Get("Accept") != tt.wantAccept || h.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("%s sent User-Agent %q, Accept %q, Accept-Encoding %q; want %q, %q, gzip",
+				tt.path, h.Get("User-Agent"), h.Get("Accept"), h.Get("Accept-Encoding"), tt.wantUA, tt.wantAccept)
+		}
+	}
+}
+
+func TestSummaryLinesDedupesMutations(t *testing.T) {
+	answer := `## Transferts
+**Mutations détectées**
+Voici les mutations :
+- Tadej Pogačar : UAE Team Emirates → Cofidis
+* **Tadej Pogačar** (UAE) → Cofidis
+- tadej pogačar - rumeur
+- Remco Evenepoel → Red Bull
+- ok
+`
+	want := []string{"Tadej Pogačar : UAE Team Emirates → Cofidis", "Remco Evenepoel → Red Bull"}
+	if got := summaryLines(answer); !slices.Equal(got, want) {
+		t.Errorf("summaryLines = %q, want %q", got, want)
+	}
+}
+
+// roundTripFunc serves feed requests in-process, standing in for the network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestFeedClientUsesInjectedTransport(t *testing.T) {
+	saved := feedClient.Transport
+	t.Cleanup(func() { feedClient.Transport = saved })
+	var hosts []string
+	feedClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "application/rss+xml")
+		rec.WriteString(testFeed)
+		return rec.Result(), nil // Response.Request left unset, as some custom transports do
+
+	})
+
+	items, srcURL, _, _, err := fetchFirstWorkingFeed(context.Background(), []string{"https://feeds.invalid/rss"}, nil, 5, true)
+	if err != nil {
+		t.Fatalf("fetchFirstWorkingFeed: %v", err)
+	}
+	if srcURL != "https://feeds.invalid/rss" || len(items) != 2 || !slices.Equal(hosts, []string{"feeds.invalid"}) {
+		t.Errorf("got %d items from %q, transport saw %q; want the 2 test items through the injected transport", len(items), srcURL, hosts)
+	}
+	if proxy := newFeedTransport().Proxy; proxy == nil {
+		t.Error("the default feed transport ignores HTTP_PROXY")
+	}
+}
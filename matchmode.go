@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// matchMode decides when a title counts as transfer news (MATCH_MODE).
+type matchMode string
+
+const (
+	// matchAny accepts a title containing at least one keyword.
+	matchAny matchMode = "any"
+	// matchAll accepts a title only if it contains every keyword.
+	matchAll matchMode = "all"
+	// matchThreshold accepts a title whose summed keyword weights reach transferMatchThreshold.
+	matchThreshold matchMode = "threshold"
+)
+
+var (
+	transferMatchMode      = matchAny
+	transferMatchThreshold = 2.0 // MATCH_THRESHOLD
+)
+
+// transferKeywordWeights scores keywords in threshold mode; keywords not listed weigh 1.
+// Strong signals weigh more than verbs that also appear in race reports ("arrive", "engage").
+var transferKeywordWeights = map[string]float64{
+	"transfert": 2, "transfer": 2, "mercato": 2, "mutation": 2, "signe": 2, "signature": 2,
+	"arrive": 0.5, "engage": 0.5, "renforce": 0.5,
+}
+
+func parseMatchMode(s string) (matchMode, error) {
+	switch m := matchMode(s); m {
+	case matchAny, matchAll, matchThreshold:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid match mode %q (want %s, %s or %s)", s, matchAny, matchAll, matchThreshold)
+}
+
+// matchesKeywords applies mode to title (already lowercased) against keywords.
+func matchesKeywords(title string, keywords []string, mode matchMode, threshold float64) bool {
+	switch mode {
+	case matchAll:
+		for _, kw := range keywords {
+			if !strings.Contains(title, kw) {
+				return false
+			}
+		}
+		return len(keywords) > 0
+	case matchThreshold:
+		var score float64
+		for _, kw := range keywords {
+			if strings.Contains(title, kw) {
+				score += keywordWeight(kw)
+			}
+		}
+		return score >= threshold
+	default:
+		for _, kw := range keywords {
+			if strings.Contains(title, kw) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func keywordWeight(kw string) float64 {
+	if w, ok := transferKeywordWeights[kw]; ok {
+		return w
+	}
+	return 1
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchesKeywordsModes(t *testing.T) {
+	keywords := []string{"transfert", "signe", "arrive", "engage"}
+	titles := []string{
+		"transfert : ayuso signe chez lidl-trek",        // transfert 2 + signe 2
+		"pogačar arrive en tête et engage la descente",  // arrive 0.5 + engage 0.5
+		"transfert, signe, arrive, engage : tout y est", // every keyword
+		"il signe son retour",                           // signe 2
+		"résultats de l'étape",                          // none
+	}
+	tests := []struct {
+		mode matchMode
+		want []int
+	}{
+		{matchAny, []int{0, 1, 2, 3}},
+		{matchAll, []int{2}},
+		{matchThreshold, []int{0, 2, 3}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for i, title := range titles {
+			if matchesKeywords(title, keywords, tt.mode, 2) {
+				got = append(got, i)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: matched titles %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+
+	if matchesKeywords("transfert", nil, matchAll, 2) {
+		t.Error("all: an empty keyword list matched")
+	}
+	if !matchesKeywords("pogačar arrive et engage", keywords, matchThreshold, 1) {
+		t.Error("threshold 1: two weak keywords (0.5 each) did not match")
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for _, s := range []string{"any", "all", "threshold"} {
+		if m, err := parseMatchMode(s); err != nil || string(m) != s {
+			t.Errorf("parseMatchMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := parseMatchMode("some"); err == nil {
+		t.Error(`parseMatchMode("some") succeeded`)
+	}
+}
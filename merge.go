@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// mergeOrder controls how items coming from several feeds are combined into a single context.
+type mergeOrder string
+
+const (
+	// mergeFeedPriority keeps items grouped by feed, in the order feeds are configured.
+	mergeFeedPriority mergeOrder = "feed-priority"
+	// mergeInterleave takes one item from each feed in turn (round-robin).
+	mergeInterleave mergeOrder = "interleave"
+	// mergeRecency sorts all items by publication date, newest first.
+	mergeRecency mergeOrder = "recency"
+)
+
+// feedMergeOrder is the merge order applied by fetchFeedContext (MERGE_ORDER).
+// Recency by default, so fresh articles come first whatever feed they are from.
+var feedMergeOrder = mergeRecency
+
+func parseMergeOrder(s string) (mergeOrder, error) {
+	switch o := mergeOrder(s); o {
+	case mergeFeedPriority, mergeInterleave, mergeRecency:
+		return o, nil
+	}
+	return "", fmt.Errorf("invalid merge order %q (want %s, %s or %s)", s, mergeFeedPriority, mergeInterleave, mergeRecency)
+}
+
+// mergeFeedItems flattens per-feed item lists according to order. The result is deterministic for a given input.
+func mergeFeedItems(perFeed [][]rssItem, order mergeOrder) []rssItem {
+	var merged []rssItem
+	switch order {
+	case mergeInterleave:
+		for i := 0; ; i++ {
+			added := false
+			for _, items := range perFeed {
+				if i < len(items) {
+					merged = append(merged, items[i])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	case mergeRecency:
+		for _, items := range perFeed {
+			merged = append(merged, items...)
+		}
+		// Stable sort so items with equal (or unparseable) dates keep their feed-priority order.
+		sort.SliceStable(merged, func(i, j int) bool { return newerItem(merged[i], merged[j]) })
+	default:
+		for _, items := range perFeed {
+			merged = append(merged, items...)
+		}
+	}
+	return merged
+}
+
+// newerItem orders a before b when its date is more recent; items with a parseable date come first.
+func newerItem(a, b rssItem) bool {
+	ta, okA := parsePubDate(a.PubDate)
+	tb, okB := parsePubDate(b.PubDate)
+	if okA != okB {
+		return okA
+	}
+	return ta.After(tb)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeFeedItems(t *testing.T) {
+	item := func(title, date string) rssItem { return rssItem{Title: title, PubDate: date} }
+	perFeed := [][]rssItem{
+		{
+			item("a1", "Mon, 02 Jun 2025 10:00:00 +0000"),
+			item("a2", "Sat, 31 May 2025 10:00:00 +0000"),
+			item("a3", ""),
+			item("a4", "Thu, 29 May 2025 10:00:00 +0000"),
+		},
+		{item("b1", "Tue, 03 Jun 2025 10:00:00 +0000")},
+		nil,
+		{item("c1", ""), item("c2", "Sun, 01 Jun 2025 10:00:00 +0000")},
+	}
+	tests := []struct {
+		order mergeOrder
+		want  []string
+	}{
+		{mergeInterleave, []string{"a1", "b1", "c1", "a2", "c2", "a3", "a4"}},
+		{mergeFeedPriority, []string{"a1", "a2", "a3", "a4", "b1", "c1", "c2"}},
+		// Undated items come last, in feed-priority order.
+		{mergeRecency, []string{"b1", "a1", "c2", "a2", "a4", "a3", "c1"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, it := range mergeFeedItems(perFeed, tt.order) {
+			got = append(got, it.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestParseMergeOrder(t *testing.T) {
+	for _, s := range []string{"feed-priority", "interleave", "recency"} {
+		if o, err := parseMergeOrder(s); err != nil || string(o) != s {
+			t.Errorf("parseMergeOrder(%q) = %q, %v", s, o, err)
+		}
+	}
+	if _, err := parseMergeOrder("random"); err == nil {
+		t.Error(`parseMergeOrder("random") succeeded`)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import "strings"
+
+// fallbackMessages holds the user-visible texts used when feeds fail or nothing matches, per language.
+type fallbackMessages struct {
+	NoFeeds       string
+	NoMatch       string
+	NoTeamNews    string // format string taking the team name
+	Disclaimer    string
+	OfflineHeader string
+	Truncated     string // format string taking the number of omitted articles
+}
+
+const defaultLanguage = "fr"
+
+var fallbackMessagesByLang = map[string]fallbackMessages{
+	"fr": {
+		NoFeeds:       "- Aucun flux cyclisme accessible pour le moment. Réponds de façon générale et prudente sur les transferts récents.",
+		NoMatch:       "- Aucun article ne correspond aux critères demandés.",
+		NoTeamNews:    "Aucune mutation récente trouvée pour l'équipe %s.",
+		Disclaimer:    "⚠️ Aucune source d'actualité n'a pu être consultée : cette réponse n'est pas fondée sur des articles récents.",
+		OfflineHeader: "Résumé hors ligne (sans modèle), articles retenus par le filtre de mots-clés :",
+		Truncated:     "- (Contexte tronqué : %d articles plus anciens omis.)",
+	},
+	"en": {
+		NoFeeds:       "- No cycling feed is reachable right now. Answer in general, cautious terms about recent transfers.",
+		NoMatch:       "- No article matches the requested criteria.",
+		NoTeamNews:    "No recent transfer found for team %s.",
+		Disclaimer:    "⚠️ No news source could be reached: this answer is not grounded in recent articles.",
+		OfflineHeader: "Offline summary (no model), articles selected by the keyword filter:",
+		Truncated:     "- (Context truncated: %d older articles omitted.)",
+	},
+}
+
+// messagesFor returns the fallback messages for lang, defaulting to French.
+func messagesFor(lang string) fallbackMessages {
+	if m, ok := fallbackMessagesByLang[lang]; ok {
+		return m
+	}
+	return fallbackMessagesByLang[defaultLanguage]
+}
+
+var (
+	englishMarkers = []string{"the", "what", "which", "who", "latest", "is", "are", "has", "have", "transfers", "signed", "of", "team"}
+	frenchMarkers  = []string{"le", "la", "les", "des", "du", "quelles", "quels", "quel", "dernières", "est", "sont", "équipe", "mutations"}
+)
+
+// messagesLanguage is the language of the fallback and disclaimer messages of a cyclingRAG run:
+// answerLanguage when -lang is set, so they match the answer, or else the question's language.
+func messagesLanguage(question string) string {
+	if answerLanguage != "" {
+		return answerLanguage
+	}
+	return detectQuestionLanguage(question)
+}
+
+// detectQuestionLanguage guesses whether question is English or French by counting common words.
+// Ties, including empty questions, resolve to French.
+func detectQuestionLanguage(question string) string {
+	en, fr := 0, 0
+	for _, w := range strings.FieldsFunc(strings.ToLower(question), isClaimSeparator) {
+		for _, m := range englishMarkers {
+			if w == m {
+				en++
+			}
+		}
+		for _, m := range frenchMarkers {
+			if w == m {
+				fr++
+			}
+		}
+	}
+	if en > fr {
+		return "en"
+	}
+	return defaultLanguage
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDegradedMessagesFollowAnswerLanguage(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	savedFeeds, savedLang := cyclingFeeds, answerLanguage
+	t.Cleanup(func() { cyclingFeeds, answerLanguage = savedFeeds, savedLang })
+	cyclingFeeds = []feedConfig{{name: "down", urls: []string{srv.URL + "/down"}}}
+
+	gen := &stubGenerator{reply: replyText(`{"answer":"ok"}`, "stub")}
+	for _, tt := range []struct{ lang, disclaimer, noFeeds string }{
+		{"en", fallbackMessagesByLang["en"].Disclaimer, fallbackMessagesByLang["en"].NoFeeds},
+		{"", fallbackMessagesByLang["fr"].Disclaimer, fallbackMessagesByLang["fr"].NoFeeds},
+	} {
+		answerLanguage = tt.lang
+		out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Question: "Quelles sont les dernières mutations ?", Refresh: true})
+		if err != nil {
+			t.Fatalf("-lang %q: %v", tt.lang, err)
+		}
+		if !strings.HasPrefix(out.Answer, tt.disclaimer) {
+			t.Errorf("-lang %q: answer %q does not start with the expected disclaimer", tt.lang, out.Answer)
+		}
+		if calls := gen.calls(); !strings.Contains(calls[len(calls)-1].Prompt, tt.noFeeds) {
+			t.Errorf("-lang %q: the prompt lacks the expected fallback context", tt.lang)
+		}
+	}
+}
+
+func TestMessagesLanguage(t *testing.T) {
+	saved := answerLanguage
+	t.Cleanup(func() { answerLanguage = saved })
+
+	answerLanguage = ""
+	if got := messagesLanguage("What are the latest transfers?"); got != "en" {
+		t.Errorf("without -lang: got %q, want the question's language", got)
+	}
+	answerLanguage = "fr"
+	if got := messagesLanguage("What are the latest transfers?"); got != "fr" {
+		t.Errorf("with -lang fr: got %q, want fr", got)
+	}
+}
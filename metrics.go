@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsEnabled turns on metric collection and the /metrics endpoint (-metrics).
+var metricsEnabled = false
+
+// modelDurationBuckets are the upper bounds, in seconds, of model_generate_duration_seconds.
+var modelDurationBuckets = []float64{0.25, 0.5, 1, 2, 5, 10, 30, 60}
+
+// histogram is a cumulative Prometheus-style histogram over modelDurationBuckets.
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last slot is +Inf
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(modelDurationBuckets)+1)
+	}
+	i := sort.SearchFloat64s(modelDurationBuckets, v)
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// metricsRegistry holds the counters and histograms exported on /metrics in the Prometheus text format.
+type metricsRegistry struct {
+	mu            sync.Mutex
+	feedFetches   map[[2]string]uint64 // {feed, status}
+	feedItems     map[string]uint64
+	modelDuration map[[2]string]*histogram // {model, status}
+}
+
+var metrics = &metricsRegistry{
+	feedFetches:   make(map[[2]string]uint64),
+	feedItems:     make(map[string]uint64),
+	modelDuration: make(map[[2]string]*histogram),
+}
+
+// recordFeedFetch counts one fetch of feedURL and the items it returned.
+func (m *metricsRegistry) recordFeedFetch(feedURL string, items int, err error) {
+	if !metricsEnabled {
+		return
+	}
+	feed := redactURL(feedURL)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feedFetches[[2]string{feed, outcome(err)}]++
+	m.feedItems[feed] += uint64(items)
+}
+
+// recordGenerate observes the duration of one model call.
+func (m *metricsRegistry) recordGenerate(model string, d time.Duration, err error) {
+	if !metricsEnabled {
+		return
+	}
+	key := [2]string{model, outcome(err)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.modelDuration[key]
+	if !ok {
+		h = &histogram{}
+		m.modelDuration[key] = h
+	}
+	h.observe(d.Seconds())
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// ServeHTTP writes every metric in the Prometheus text exposition format, series sorted by labels.
+func (m *metricsRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(w, "# HELP feed_fetch_total Feed fetches by feed URL and outcome.")
+	fmt.Fprintln(w, "# TYPE feed_fetch_total counter")
+	for _, k := range sortedKeys(m.feedFetches) {
+		fmt.Fprintf(w, "feed_fetch_total{feed=%s,status=%s} %d\n", promLabel(k[0]), promLabel(k[1]), m.feedFetches[k])
+	}
+
+	fmt.Fprintln(w, "# HELP feed_items_total Items returned by feed fetches.")
+	fmt.Fprintln(w, "# TYPE feed_items_total counter")
+	for _, k := range sortedKeys(m.feedItems) {
+		fmt.Fprintf(w, "feed_items_total{feed=%s} %d\n", promLabel(k), m.feedItems[k])
+	}
+
+	fmt.Fprintln(w, "# HELP model_generate_duration_seconds Duration of model calls by model and outcome.")
+	fmt.Fprintln(w, "# TYPE model_generate_duration_seconds histogram")
+	for _, k := range sortedKeys(m.modelDuration) {
+		h := m.modelDuration[k]
+		labels := fmt.Sprintf("model=%s,status=%s", promLabel(k[0]), promLabel(k[1]))
+		var cumulative uint64
+		for i, le := range modelDurationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "model_generate_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, cumulative)
+		}
+		fmt.Fprintf(w, "model_generate_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "model_generate_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(w, "model_generate_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+}
+
+// sortedKeys returns the keys of m in a stable order, so scrapes are easy to diff.
+func sortedKeys[K string | [2]string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+	return keys
+}
+
+// promLabel quotes v as a Prometheus label value.
+func promLabel(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
+
+// metricsGenerator times every call to next in model_generate_duration_seconds.
+type metricsGenerator struct {
+	next Generator
+}
+
+func (m metricsGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	start := time.Now()
+	res, err := m.next.Generate(ctx, req)
+	metrics.recordGenerate(req.Model, time.Since(start), err)
+	return res, err
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsScrape(t *testing.T) {
+	savedEnabled, savedMetrics := metricsEnabled, metrics
+	metricsEnabled = true
+	metrics = &metricsRegistry{
+		feedFetches:   make(map[[2]string]uint64),
+		feedItems:     make(map[string]uint64),
+		modelDuration: make(map[[2]string]*histogram),
+	}
+	t.Cleanup(func() { metricsEnabled, metrics = savedEnabled, savedMetrics })
+
+	srv := serveFeed(t, testFeed)
+	feedURL := srv.URL + "/rss?token=s3cret"
+	if _, err := fetchRSSItems(context.Background(), feedURL, nil, 10); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if _, err := fetchRSSItems(context.Background(), "http://127.0.0.1:1/rss", nil, 10); err == nil {
+		t.Fatal("fetching a closed port: want an error")
+	}
+
+	gen := metricsGenerator{next: &stubGenerator{}}
+	if _, err := gen.Generate(context.Background(), GenerateRequest{Model: "googleai/test", Prompt: "q"}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	failing := metricsGenerator{next: &stubGenerator{reply: func(GenerateRequest) (GenerateResult, error) {
+		return GenerateResult{}, errors.New("boom")
+	}}}
+	failing.Generate(context.Background(), GenerateRequest{Model: "googleai/test", Prompt: "q"})
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+
+	redacted := srv.URL + "/rss?token=" + redactedValue
+	for _, want := range []string{
+		"# TYPE feed_fetch_total counter",
+		`feed_fetch_total{feed="` + redacted + `",status="ok"} 1`,
+		`feed_fetch_total{feed="http://127.0.0.1:1/rss",status="error"} 1`,
+		`feed_items_total{feed="` + redacted + `"} 2`,
+		"# TYPE model_generate_duration_seconds histogram",
+		`model_generate_duration_seconds_bucket{model="googleai/test",status="ok",le="0.25"} 1`,
+		`model_generate_duration_seconds_bucket{model="googleai/test",status="ok",le="+Inf"} 1`,
+		`model_generate_duration_seconds_count{model="googleai/test",status="ok"} 1`,
+		`model_generate_duration_seconds_count{model="googleai/test",status="error"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape is missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "s3cret") {
+		t.Errorf("scrape leaks the feed token:\n%s", body)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Retry policy for model calls (MODEL_MAX_ATTEMPTS). Delays double from modelRetryBaseDelay, with jitter.
+var (
+	modelMaxAttempts    = 3
+	modelRetryBaseDelay = time.Second
+)
+
+// retryGenerator retries model calls that fail with a transient API error.
+type retryGenerator struct {
+	next        Generator
+	maxAttempts int
+}
+
+func (r retryGenerator) Generate(ctx context.Context, req GenerateRequest) (GenerateResult, error) {
+	return generateWithRetry(ctx, r.next, req, r.maxAttempts)
+}
+
+// generateWithRetry calls gen up to maxAttempts times while it fails with a transient error
+// (see isTransientModelError), backing off exponentially. Other errors are returned at once, and a
+// streamed call is not retried once a chunk has been emitted, so callers never see text twice.
+func generateWithRetry(ctx context.Context, gen Generator, req GenerateRequest, maxAttempts int) (GenerateResult, error) {
+	streamed := false
+	if stream := req.Stream; stream != nil {
+		req.Stream = func(ctx context.Context, chunk string) error {
+			streamed = true
+			return stream(ctx, chunk)
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleepCtx(ctx, backoffDelay(modelRetryBaseDelay, attempt-1)); err != nil {
+				return GenerateResult{}, lastErr
+			}
+			slog.Info("model retry", "attempt", attempt, "max_attempts", maxAttempts, "model", req.Model, "err", lastErr)
+		}
+
+		res, err := gen.Generate(ctx, req)
+		if err == nil || errors.Is(err, errMalformedOutput) {
+			return res, err
+		}
+		lastErr = err
+		if streamed || !isTransientModelError(err) {
+			break
+		}
+	}
+	return GenerateResult{}, lastErr
+}
+
+// isTransientModelError reports whether a model call failing with err is worth retrying:
+// rate limiting (429), server-side failures (5xx) and network errors.
+func isTransientModelError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
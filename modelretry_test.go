@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// scriptedReplies returns a stub reply failing with each error of errs in turn, then answering "ok".
+func scriptedReplies(errs ...error) func(GenerateRequest) (GenerateResult, error) {
+	return func(req GenerateRequest) (GenerateResult, error) {
+		if len(errs) > 0 {
+			err := errs[0]
+			errs = errs[1:]
+			return GenerateResult{}, err
+		}
+		return GenerateResult{Text: "ok", Model: req.Model}, nil
+	}
+}
+
+func TestGenerateWithRetry(t *testing.T) {
+	saved := modelRetryBaseDelay
+	modelRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { modelRetryBaseDelay = saved })
+
+	overloaded := genai.APIError{Code: http.StatusServiceUnavailable, Message: "overloaded"}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+	}{
+		{"transient errors then success", []error{overloaded, fmt.Errorf("generate: %w", genai.APIError{Code: http.StatusTooManyRequests})}, false, 3},
+		{"transient errors past the attempt limit", []error{overloaded, overloaded, overloaded}, true, 3},
+		{"non-transient error", []error{genai.APIError{Code: http.StatusBadRequest, Message: "bad request"}}, true, 1},
+		{"cancellation", []error{context.Canceled}, true, 1},
+	}
+	for _, tt := range tests {
+		gen := &stubGenerator{reply: scriptedReplies(tt.errs...)}
+		res, err := generateWithRetry(context.Background(), gen, GenerateRequest{Model: "stub", Prompt: "?"}, 3)
+		if (err != nil) != tt.wantErr || (err == nil && res.Text != "ok") {
+			t.Errorf("%s: got %+v, %v", tt.name, res, err)
+		}
+		if n := len(gen.calls()); n != tt.wantCalls {
+			t.Errorf("%s: %d calls, want %d", tt.name, n, tt.wantCalls)
+		}
+		if last := tt.errs[len(tt.errs)-1]; tt.wantErr && err != nil && err.Error() != last.Error() {
+			t.Errorf("%s: got %v, want the last error", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Mutation is one rider move extracted by cyclingRAG. Status is "officiel" or "rumeur";
+// ToTeam is "équipe inconnue" when the context does not name the destination.
+type Mutation struct {
+	Rider    string `json:"rider"`
+	FromTeam string `json:"fromTeam"`
+	ToTeam   string `json:"toTeam"`
+	Status   string `json:"status"`
+}
+
+// mutationReport is the structured output requested from the model by generateMutations.
+type mutationReport struct {
+	Answer    string     `json:"answer"`
+	Mutations []Mutation `json:"mutations"`
+}
+
+// generateMutations sends prompt to the model requesting both a readable answer and typed mutations.
+// When the model's JSON is malformed, its raw text is returned as the answer and no mutations are reported.
+func generateMutations(ctx context.Context, gen Generator, prompt string) (mutationReport, GenerateResult, error) {
+	report, res, err := generateData[mutationReport](ctx, gen, GenerateRequest{Model: modelName, Prompt: prompt})
+	if errors.Is(err, errMalformedOutput) {
+		slog.Warn("sortie JSON du modèle invalide, mutations ignorées", "err", err)
+		return mutationReport{Answer: res.Text}, res, nil
+	}
+	if err != nil {
+		return mutationReport{}, res, err
+	}
+	report.Mutations = dedupeMutations(report.Mutations)
+	return *report, res, nil
+}
+
+// normalizeRiderName is the key used to compare rider names: diacritics stripped, lowercased,
+// punctuation dropped and whitespace collapsed, so "Tadej Pogačar" gives "tadej pogacar".
+func normalizeRiderName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return ' '
+		}
+		return r
+	}, foldAccents(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// sameRider reports whether two normalized names designate the same rider: they are equal, or the
+// shorter one is part of the longer one and ends with its surname ("pogacar", "tadej pogacar").
+func sameRider(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ta, tb := strings.Fields(a), strings.Fields(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return false
+	}
+	if len(ta) > len(tb) {
+		ta, tb = tb, ta
+	}
+	if ta[len(ta)-1] != tb[len(tb)-1] {
+		return false
+	}
+	for _, t := range ta {
+		if !slices.Contains(tb, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupeMutations merges the mutations of the same rider (see sameRider), keeping at the position
+// of the first one the record with the most non-empty fields, display name unchanged.
+func dedupeMutations(mutations []Mutation) []Mutation {
+	var out []Mutation
+	var keys []string
+	for _, m := range mutations {
+		key := normalizeRiderName(m.Rider)
+		i := slices.IndexFunc(keys, func(k string) bool { return key != "" && sameRider(k, key) })
+		if i < 0 {
+			out = append(out, m)
+			keys = append(keys, key)
+			continue
+		}
+		if filledFields(m) > filledFields(out[i]) {
+			out[i], keys[i] = m, key
+		}
+	}
+	return out
+}
+
+// mutationsInAnswer keeps the mutations whose rider's surname is still named in answer, so a
+// critique pass that drops a move from the text also drops it from the structured output.
+func mutationsInAnswer(mutations []Mutation, answer string) []Mutation {
+	text := normalizeRiderName(answer)
+	var kept []Mutation
+	for _, m := range mutations {
+		names := strings.Fields(normalizeRiderName(m.Rider))
+		if len(names) > 0 && containsWord(text, names[len(names)-1]) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// filledFields counts the non-blank fields of m.
+func filledFields(m Mutation) int {
+	n := 0
+	for _, f := range []string{m.Rider, m.FromTeam, m.ToTeam, m.Status} {
+		if strings.TrimSpace(f) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// logMutations prints the structured mutations returned by cyclingRAG to stdout, one per line.
+func logMutations(mutations []Mutation) {
+	fmt.Println("Mutations détectées :")
+	for _, m := range mutations {
+		fmt.Printf("- %s — %s -> %s (%s)\n", m.Rider, m.FromTeam, m.ToTeam, m.Status)
+	}
+}
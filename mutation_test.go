@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSameRider(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Pogačar", "Tadej Pogačar", true},
+		{"Tadej Pogacar", "TADEJ POGAČAR", true},
+		{"T. Pogačar", "Tadej Pogačar", false},
+		{"Adam Yates", "Simon Yates", false},
+		{"Yates", "Simon Yates", true},
+		{"Tadej", "Tadej Pogačar", false},
+		{"", "Pogačar", false},
+	}
+	for _, tt := range tests {
+		if got := sameRider(normalizeRiderName(tt.a), normalizeRiderName(tt.b)); got != tt.want {
+			t.Errorf("sameRider(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeMutations(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []Mutation
+		want []Mutation
+	}{
+		{
+			name: "surname and full name merge into the most complete record",
+			in: []Mutation{
+				{Rider: "Pogačar", ToTeam: "Cofidis"},
+				{Rider: "Tadej Pogačar", FromTeam: "UAE", ToTeam: "Cofidis", Status: statusRumour},
+			},
+			want: []Mutation{{Rider: "Tadej Pogačar", FromTeam: "UAE", ToTeam: "Cofidis", Status: statusRumour}},
+		},
+		{
+			name: "accent and case variants merge, keeping the first display name on ties",
+			in: []Mutation{
+				{Rider: "Tadej Pogačar", ToTeam: "Cofidis", Status: statusRumour},
+				{Rider: "TADEJ POGACAR", ToTeam: "Cofidis", Status: statusRumour},
+			},
+			want: []Mutation{{Rider: "Tadej Pogačar", ToTeam: "Cofidis", Status: statusRumour}},
+		},
+		{
+			name: "brothers stay apart",
+			in: []Mutation{
+				{Rider: "Adam Yates", ToTeam: "Jayco"},
+				{Rider: "Simon Yates", ToTeam: "Visma"},
+			},
+			want: []Mutation{
+				{Rider: "Adam Yates", ToTeam: "Jayco"},
+				{Rider: "Simon Yates", ToTeam: "Visma"},
+			},
+		},
+		{
+			name: "blank riders are never merged",
+			in:   []Mutation{{ToTeam: "Cofidis"}, {ToTeam: "Arkéa"}},
+			want: []Mutation{{ToTeam: "Cofidis"}, {ToTeam: "Arkéa"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := dedupeMutations(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: dedupeMutations = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// How cyclingRAG turns its context into an answer (SUMMARY_MODE).
+const (
+	summaryModel   = "model"
+	summaryOffline = "offline"
+)
+
+// summaryMode selects between the model and the offline, template-only summary.
+var summaryMode = summaryModel
+
+// offlineModelName is reported as the model of answers built without calling Generate.
+const offlineModelName = "offline"
+
+func parseSummaryMode(s string) (string, error) {
+	switch s {
+	case summaryModel, summaryOffline:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid summary mode %q (want %s or %s)", s, summaryModel, summaryOffline)
+}
+
+// offlineSummary formats the context snippets under header as an answer without calling the model.
+func offlineSummary(header string, snippets []string) string {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteByte('\n')
+	for _, s := range snippets {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Mutation values used by offlineMutations, matching those the prompt asks the model for.
+const (
+	unknownTeam    = "équipe inconnue"
+	statusOfficial = "officiel"
+	statusRumour   = "rumeur"
+)
+
+// offlineMutations derives a Mutation from each item title without calling the model. The rider is
+// the run of capitalized words opening the title, after any "Rubrique :" label; the teams are the
+// known teams tagged by annotateTeams, in title order, and classifyEventType tells the origin from
+// the destination. Titles without a rider are skipped.
+func offlineMutations(items []rssItem) []Mutation {
+	var mutations []Mutation
+	for _, it := range items {
+		teams := teamsByPosition(it.Title, it.Teams)
+		rider := titleRider(it.Title)
+		if rider == "" || slices.ContainsFunc(teams, func(t string) bool { return foldAccents(t) == foldAccents(rider) }) {
+			continue
+		}
+		m := Mutation{Rider: rider, ToTeam: unknownTeam, Status: statusRumour}
+		switch {
+		case classifyEventType(it.Title) == eventExtension && len(teams) > 0:
+			m.FromTeam, m.ToTeam = teams[0], teams[0]
+		case len(teams) >= 2:
+			m.FromTeam, m.ToTeam = teams[0], teams[1]
+		case len(teams) == 1 && classifyEventType(it.Title) == eventDeparture:
+			m.FromTeam = teams[0]
+		case len(teams) == 1:
+			m.ToTeam = teams[0]
+		}
+		if folded := foldAccents(it.Title); strings.Contains(folded, "officiel") || strings.Contains(folded, "official") {
+			m.Status = statusOfficial
+		}
+		mutations = append(mutations, m)
+	}
+	return dedupeMutations(mutations)
+}
+
+// titleRider returns the capitalized words opening title once any label ending with ':' is
+// removed, e.g. "Tadej Pogačar" for "Mercato : Tadej Pogačar rejoint Cofidis".
+func titleRider(title string) string {
+	if i := strings.LastIndex(title, ":"); i >= 0 {
+		title = title[i+1:]
+	}
+	var words []string
+	for _, w := range strings.Fields(title) {
+		if r, _ := utf8.DecodeRuneInString(w); !unicode.IsUpper(r) {
+			break
+		}
+		words = append(words, strings.TrimRight(w, ",;"))
+	}
+	return strings.Join(words, " ")
+}
+
+// teamsByPosition returns teams sorted by where they, or one of their aliases, first appear in
+// title, ignoring case and accents.
+func teamsByPosition(title string, teams []string) []string {
+	folded := foldAccents(title)
+	position := func(team string) int {
+		first := len(folded)
+		for _, name := range teamNames(team) {
+			if i := strings.Index(folded, name); i >= 0 && i < first {
+				first = i
+			}
+		}
+		return first
+	}
+	sorted := slices.Clone(teams)
+	slices.SortStableFunc(sorted, func(a, b string) int { return position(a) - position(b) })
+	return sorted
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOfflineSummaryDoesNotCallTheModel(t *testing.T) {
+	serveFeed(t, `<rss><channel>
+<item><title>Mercato : Tadej Pogačar quitte UAE Team Emirates pour Cofidis</title><link>https://example.com/1</link></item>
+<item><title>Officiel : Julian Alaphilippe prolonge avec Cofidis</title><link>https://example.com/2</link></item>
+</channel></rss>`)
+	savedMode, savedTeams := summaryMode, knownTeams
+	summaryMode, knownTeams = summaryOffline, []string{"Cofidis", "UAE Team Emirates"}
+	t.Cleanup(func() { summaryMode, knownTeams = savedMode, savedTeams })
+
+	gen := &stubGenerator{reply: func(GenerateRequest) (GenerateResult, error) {
+		t.Error("the offline summary called the model")
+		return GenerateResult{}, nil
+	}}
+	out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+	if err != nil {
+		t.Fatalf("RunFeedRAG: %v", err)
+	}
+	if out.Model != offlineModelName {
+		t.Errorf("Model = %q, want %q", out.Model, offlineModelName)
+	}
+	if !strings.Contains(out.Answer, "Tadej Pogačar quitte UAE Team Emirates pour Cofidis") || !strings.Contains(out.Answer, "Julian Alaphilippe prolonge") {
+		t.Errorf("the answer does not list the fixture items:\n%s", out.Answer)
+	}
+	want := []Mutation{
+		{Rider: "Tadej Pogačar", FromTeam: "UAE Team Emirates", ToTeam: "Cofidis", Status: statusRumour},
+		{Rider: "Julian Alaphilippe", FromTeam: "Cofidis", ToTeam: "Cofidis", Status: statusOfficial},
+	}
+	if !reflect.DeepEqual(out.Mutations, want) {
+		t.Errorf("Mutations = %+v, want %+v", out.Mutations, want)
+	}
+}
+
+func TestOfflineMutations(t *testing.T) {
+	tests := []struct {
+		title string
+		teams []string
+		want  []Mutation
+	}{
+		{"Remco Evenepoel rejoint Red Bull", []string{"Red Bull"}, []Mutation{{Rider: "Remco Evenepoel", ToTeam: "Red Bull", Status: statusRumour}}},
+		{"Mads Pedersen quitte Lidl-Trek", []string{"Lidl-Trek"}, []Mutation{{Rider: "Mads Pedersen", FromTeam: "Lidl-Trek", ToTeam: unknownTeam, Status: statusRumour}}},
+		{"Cofidis recrute un sprinteur", []string{"Cofidis"}, nil},
+		{"le mercato s'emballe", nil, nil},
+	}
+	for _, tt := range tests {
+		got := offlineMutations([]rssItem{{Title: tt.title, Teams: tt.teams}})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("offlineMutations(%q) = %+v, want %+v", tt.title, got, tt.want)
+		}
+	}
+}
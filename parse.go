@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+}
+
+type atomFeed struct {
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// rdfFeed is an RSS 1.0 document: a <rdf:RDF> root whose <item> elements are siblings of the
+// <channel>, which only lists them by reference (rdf:li).
+type rdfFeed struct {
+	ChannelLink string `xml:"channel>link"`
+	Items       []struct {
+		Title string `xml:"title"`
+		Link  string `xml:"link"`
+		Date  string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"item"`
+}
+
+// jsonFeed is the subset of a JSON Feed 1.x document (https://jsonfeed.org) turned into rssItems.
+type jsonFeed struct {
+	HomePageURL string `json:"home_page_url"`
+	Items       []struct {
+		Title         string `json:"title"`
+		URL           string `json:"url"`
+		DatePublished string `json:"date_published"`
+	} `json:"items"`
+}
+
+// parseFeedDocument decodes a JSON Feed, RSS 2.0, RSS 1.0 (RDF) or Atom 1.0 document. JSON is recognized from
+// contentType or, failing that, from a leading '{'; XML formats are chosen from their root element.
+// It returns the items and the feed-level links relative item links are resolved against.
+func parseFeedDocument(body []byte, contentType string) ([]rssItem, []string, error) {
+	if isJSONFeed(body, contentType) {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch root.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, nil, err
+		}
+		return feed.Channel.Items, feed.Channel.Links, nil
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, nil, err
+		}
+		items := make([]rssItem, 0, len(feed.Entries))
+		for _, e := range feed.Entries {
+			date := e.Updated
+			if date == "" {
+				date = e.Published
+			}
+			items = append(items, rssItem{Title: e.Title, Link: atomAlternate(e.Links), PubDate: date})
+		}
+		var links []string
+		if l := atomAlternate(feed.Links); l != "" {
+			links = append(links, l)
+		}
+		return items, links, nil
+	case "RDF":
+		var feed rdfFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, nil, err
+		}
+		items := make([]rssItem, 0, len(feed.Items))
+		for _, it := range feed.Items {
+			items = append(items, rssItem{Title: it.Title, Link: it.Link, PubDate: it.Date})
+		}
+		var links []string
+		if feed.ChannelLink != "" {
+			links = append(links, feed.ChannelLink)
+		}
+		return items, links, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported feed root element <%s>", root.Local)
+}
+
+// isJSONFeed reports whether body should be decoded as a JSON Feed.
+func isJSONFeed(body []byte, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "application/feed+json", "application/json":
+			return true
+		}
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+func parseJSONFeed(body []byte) ([]rssItem, []string, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, nil, fmt.Errorf("decoding JSON Feed: %w", err)
+	}
+	items := make([]rssItem, 0, len(feed.Items))
+	for _, it := range feed.Items {
+		items = append(items, rssItem{Title: it.Title, Link: it.URL, PubDate: it.DatePublished})
+	}
+	var links []string
+	if feed.HomePageURL != "" {
+		links = append(links, feed.HomePageURL)
+	}
+	return items, links, nil
+}
+
+// rootElement returns the name of the first element of an XML document.
+func rootElement(body []byte) (xml.Name, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return xml.Name{}, errors.New("empty feed document")
+		}
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name, nil
+		}
+	}
+}
+
+// atomAlternate picks the href of the alternate link (rel="alternate" or no rel), if any.
+func atomAlternate(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// plainTitle turns a decoded feed title into plain text: HTML tags left by CDATA sections or
+// escaped markup are dropped and HTML entities ("&amp;", "&#233;") decoded. The XML decoder
+// already resolved one level of escaping, so entities are decoded exactly once more; text
+// without '<' or '&' is returned unchanged.
+func plainTitle(title string) string {
+	if !strings.ContainsAny(title, "<&") {
+		return title
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(title))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			b.Write(z.Text())
+		}
+	}
+}
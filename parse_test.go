@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fetchFixture serves body as contentType from a stub server and returns the items
+// downloadFeedItems reads from its /feed path.
+func fetchFixture(t *testing.T, contentType, body string) []rssItem {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	items, err := downloadFeedItems(context.Background(), srv.URL+"/feed", nil, 10)
+	if err != nil {
+		t.Fatalf("downloadFeedItems(%s): %v", contentType, err)
+	}
+	return items
+}
+
+func TestPlainTitle(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Pogačar rejoint Cofidis", "Pogačar rejoint Cofidis"},
+		{"<b>Pogačar</b> <i>rejoint <span>Cofidis</span></i>", "Pogačar rejoint Cofidis"},
+		{"Tom &amp; Jerry", "Tom & Jerry"},
+		{"Tom &amp;amp; Jerry", "Tom &amp; Jerry"},
+		{"L&#39;&#201;quipe", "L'Équipe"},
+		{"1 < 2", "1 < 2"},
+	}
+	for _, tt := range tests {
+		if got := plainTitle(tt.in); got != tt.want {
+			t.Errorf("plainTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFeedTitlesArePlainText(t *testing.T) {
+	srv := serveFeed(t, `<rss><channel>
+<item><title><![CDATA[<b>Pogačar</b> rejoint <a href="#">Cofidis</a> &amp; UAE]]></title></item>
+<item><title>Tom &amp;amp; Jerry</title></item>
+<item><title>&lt;em&gt;Roglič&lt;/em&gt; prolonge</title></item>
+</channel></rss>`)
+	items, err := fetchRSSItems(context.Background(), srv.URL+"/titles", nil, 5)
+	if err != nil {
+		t.Fatalf("fetchRSSItems: %v", err)
+	}
+	want := []string{"Pogačar rejoint Cofidis & UAE", "Tom & Jerry", "Roglič prolonge"}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i, it := range items {
+		if it.Title != want[i] {
+			t.Errorf("item %d: title %q, want %q", i, it.Title, want[i])
+		}
+	}
+}
+
+func TestParseAtomFeed(t *testing.T) {
+	items := fetchFixture(t, "application/atom+xml", `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <link rel="self" href="https://velo.example/atom.xml"/>
+  <link rel="alternate" href="https://velo.example/actu/"/>
+  <entry>
+    <title type="html">&lt;b&gt;Ayuso&lt;/b&gt; rejoint Lidl-Trek</title>
+    <link rel="enclosure" href="https://velo.example/ayuso.jpg"/>
+    <link rel="alternate" href="ayuso.html"/>
+    <published>2025-06-01T08:00:00Z</published>
+    <updated>2025-06-02T09:30:00+02:00</updated>
+  </entry>
+  <entry>
+    <title>Carapaz signe chez EF</title>
+    <link href="https://velo.example/carapaz"/>
+    <published>2025-05-30T10:00:00Z</published>
+  </entry>
+</feed>`)
+	want := []rssItem{
+		{Title: "Ayuso rejoint Lidl-Trek", Link: "https://velo.example/actu/ayuso.html", PubDate: "2025-06-02T09:30:00+02:00"},
+		{Title: "Carapaz signe chez EF", Link: "https://velo.example/carapaz", PubDate: "2025-05-30T10:00:00Z"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	const doc = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Blog vélo",
+  "home_page_url": "https://blog.example/",
+  "items": [
+    {"id": "1", "title": "Transfert : Pidcock quitte Ineos", "url": "/pidcock", "date_published": "2025-06-03T07:15:00+02:00"},
+    {"id": "2", "title": "Mercato &amp; rumeurs", "url": "https://blog.example/rumeurs"}
+  ]
+}`
+	want := []rssItem{
+		{Title: "Transfert : Pidcock quitte Ineos", Link: "https://blog.example/pidcock", PubDate: "2025-06-03T07:15:00+02:00"},
+		{Title: "Mercato & rumeurs", Link: "https://blog.example/rumeurs"},
+	}
+	for _, contentType := range []string{
+		"application/feed+json",
+		"application/json; charset=utf-8",
+		// Mislabelled: the leading '{' still identifies a JSON Feed.
+		"application/rss+xml",
+	} {
+		if items := fetchFixture(t, contentType, doc); !reflect.DeepEqual(items, want) {
+			t.Errorf("served as %s: items =\n%+v\nwant\n%+v", contentType, items, want)
+		}
+	}
+	if _, ok := parsePubDate(want[0].PubDate); !ok {
+		t.Errorf("date_published %q does not parse", want[0].PubDate)
+	}
+}
+
+func TestParseRDFFeed(t *testing.T) {
+	items := fetchFixture(t, "application/rdf+xml", `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://cyclisme.example/">
+    <title>Cyclisme</title>
+    <link>https://cyclisme.example/</link>
+    <items><rdf:Seq><rdf:li rdf:resource="https://cyclisme.example/1"/></rdf:Seq></items>
+  </channel>
+  <item rdf:about="https://cyclisme.example/1">
+    <title>Gaudu prolonge avec Groupama-FDJ</title>
+    <link>articles/gaudu</link>
+    <dc:date>2025-06-04T18:00:00+02:00</dc:date>
+  </item>
+  <item rdf:about="https://cyclisme.example/2">
+    <title>Martin signe chez Cofidis</title>
+    <link>https://cyclisme.example/martin</link>
+  </item>
+</rdf:RDF>`)
+	want := []rssItem{
+		{Title: "Gaudu prolonge avec Groupama-FDJ", Link: "https://cyclisme.example/articles/gaudu", PubDate: "2025-06-04T18:00:00+02:00"},
+		{Title: "Martin signe chez Cofidis", Link: "https://cyclisme.example/martin"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pubDateLayouts are tried in order by parsePubDate. Besides RFC 1123 (RSS) and RFC 3339 (Atom),
+// they cover single-digit days, missing weekdays, RFC 3339 without seconds or time, and the numeric
+// formats some French sites use.
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"2006-01-02",
+}
+
+// frenchDateNames maps French day and month abbreviations to the English ones time.Parse expects.
+var frenchDateNames = strings.NewReplacer(
+	"lun.", "Mon", "mar.", "Tue", "mer.", "Wed", "jeu.", "Thu", "ven.", "Fri", "sam.", "Sat", "dim.", "Sun",
+	"janv.", "Jan", "févr.", "Feb", "mars", "Mar", "avr.", "Apr", "mai", "May", "juin", "Jun",
+	"juil.", "Jul", "août", "Aug", "sept.", "Sep", "oct.", "Oct", "nov.", "Nov", "déc.", "Dec",
+)
+
+// parsePubDate parses a feed publication date, reporting whether any known layout matched.
+// Dates without a zone (the numeric French formats) are read as Europe/Paris time.
+func parsePubDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	candidates := []string{s}
+	if fr := frenchDateNames.Replace(strings.ToLower(s)); fr != strings.ToLower(s) {
+		candidates = append(candidates, fr)
+	}
+	for _, c := range candidates {
+		for _, layout := range pubDateLayouts {
+			if t, err := time.ParseInLocation(layout, c, parisLocation); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parisLocation is the zone assumed for dates without one; UTC if the tz database is unavailable.
+var parisLocation = func() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Paris"); err == nil {
+		return loc
+	}
+	return time.UTC
+}()
+
+// Publication-age filter (-max-age): older items are dropped before the context is built.
+var (
+	maxItemAge       time.Duration // 0 keeps every item
+	keepUndatedItems = true        // -keep-undated: items whose date does not parse survive the filter
+)
+
+// filterByAge drops the items published more than maxAge before now, and the undated ones unless
+// keepUndated is set. It also returns how many items were dropped.
+func filterByAge(items []rssItem, maxAge time.Duration, keepUndated bool, now time.Time) ([]rssItem, int) {
+	cutoff := now.Add(-maxAge)
+	var kept []rssItem
+	for _, it := range items {
+		t, ok := parsePubDate(it.PubDate)
+		if (!ok && keepUndated) || (ok && !t.Before(cutoff)) {
+			kept = append(kept, it)
+		}
+	}
+	return kept, len(items) - len(kept)
+}
+
+// parseAge parses a -max-age value: a Go duration ("72h") or a number of days ("30d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(strings.TrimSpace(s), "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(strings.TrimSpace(s))
+}
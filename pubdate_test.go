@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParsePubDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"Mon, 02 Jun 2025 10:00:00 +0200", time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"2025-06-02T10:00:00Z", time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)},
+		{"2025-06-02T10:00+02:00", time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"2025-06-02T10:00Z", time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)},
+		{"2025-06-02", time.Date(2025, 6, 2, 0, 0, 0, 0, parisLocation)},
+		{"02/06/2025 10:00", time.Date(2025, 6, 2, 10, 0, 0, 0, parisLocation)},
+		{"lun., 2 juin 2025 10:00:00 +0200", time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, ok := parsePubDate(tt.in)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parsePubDate(%q) = %v, %v; want %v", tt.in, got, ok, tt.want)
+		}
+	}
+	for _, in := range []string{"", "hier", "2025-13-02"} {
+		if _, ok := parsePubDate(in); ok {
+			t.Errorf("parsePubDate(%q) succeeded", in)
+		}
+	}
+}
+
+func TestFilterByAge(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	items := []rssItem{
+		{Title: "recent", PubDate: "Mon, 09 Jun 2025 12:00:00 +0000"},
+		{Title: "old", PubDate: "Sat, 01 Mar 2025 12:00:00 +0000"},
+		{Title: "undated", PubDate: "la semaine dernière"},
+	}
+	for _, tt := range []struct {
+		keepUndated bool
+		want        []string
+	}{
+		{true, []string{"recent", "undated"}},
+		{false, []string{"recent"}},
+	} {
+		kept, dropped := filterByAge(items, 7*24*time.Hour, tt.keepUndated, now)
+		var got []string
+		for _, it := range kept {
+			got = append(got, it.Title)
+		}
+		if !slices.Equal(got, tt.want) || dropped != len(items)-len(tt.want) {
+			t.Errorf("keepUndated=%v: kept %q, dropped %d; want %q", tt.keepUndated, got, dropped, tt.want)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{"30d": 30 * 24 * time.Hour, " 72h ": 72 * time.Hour, "0d": 0}
+	for in, want := range tests {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"xd", "trente jours", ""} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q): want an error", in)
+		}
+	}
+}
+
+func TestMaxAgeReportsNoRecentItem(t *testing.T) {
+	serveFeed(t, `<rss><channel><item><title>Transfert : Pogačar rejoint Cofidis</title><pubDate>Sat, 01 Mar 2025 12:00:00 +0000</pubDate></item></channel></rss>`)
+	savedAge, savedKeep := maxItemAge, keepUndatedItems
+	maxItemAge, keepUndatedItems = 24*time.Hour, true
+	t.Cleanup(func() { maxItemAge, keepUndatedItems = savedAge, savedKeep })
+
+	_, _, stats, err := fetchFeedContext(context.Background(), contextOptions{Refresh: true})
+	if err != nil || stats.Reason != reasonNoRecentItem || len(stats.ContextItems) != 0 {
+		t.Errorf("Reason %q with %d items, err %v; want %q and no item", stats.Reason, len(stats.ContextItems), err, reasonNoRecentItem)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on qaFlow questions: at most maxQuestionChars runes (MAX_QUESTION_CHARS, 0 means no cap);
+// longer questions are rejected, or cut at a word boundary with -truncate-question.
+var (
+	maxQuestionChars = 4000
+	truncateQuestion = false
+)
+
+// validateQuestion trims q and checks it against maxQuestionChars, returning the question to send
+// to the model or an error wrapping ErrInvalidQuestion.
+func validateQuestion(q string) (string, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return "", fmt.Errorf("%w: the question is empty", ErrInvalidQuestion)
+	}
+	n := utf8.RuneCountInString(q)
+	if maxQuestionChars <= 0 || n <= maxQuestionChars {
+		return q, nil
+	}
+	if !truncateQuestion {
+		return "", fmt.Errorf("%w: %d characters, more than the %d allowed (MAX_QUESTION_CHARS)", ErrInvalidQuestion, n, maxQuestionChars)
+	}
+	slog.Warn("question tronquée (MAX_QUESTION_CHARS)", "chars", n, "max_chars", maxQuestionChars)
+	return truncateText(q, maxQuestionChars, truncationMarker), nil
+}
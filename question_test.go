@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidateQuestion(t *testing.T) {
+	savedMax, savedTruncate, savedMarker := maxQuestionChars, truncateQuestion, truncationMarker
+	maxQuestionChars, truncationMarker = 20, "…"
+	t.Cleanup(func() { maxQuestionChars, truncateQuestion, truncationMarker = savedMax, savedTruncate, savedMarker })
+
+	long := "Quels coureurs rejoignent Cofidis en 2026 ?"
+	tests := []struct {
+		name     string
+		q        string
+		truncate bool
+		want     string
+		wantErr  bool
+	}{
+		{"empty", "", false, "", true},
+		{"blank", " \t\n", false, "", true},
+		{"normal", "  Où va Pogačar ?  ", false, "Où va Pogačar ?", false},
+		{"exactly the limit", strings.Repeat("é", 20), false, strings.Repeat("é", 20), false},
+		{"oversized rejected", long, false, "", true},
+		{"oversized truncated", long, true, "Quels coureurs…", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncateQuestion = tt.truncate
+			got, err := validateQuestion(tt.q)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuestion) {
+					t.Errorf("validateQuestion(%q) error = %v, want %v", tt.q, err, ErrInvalidQuestion)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("validateQuestion(%q) = %q, %v; want %q", tt.q, got, err, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > maxQuestionChars {
+				t.Errorf("validateQuestion(%q) kept %d characters, more than %d", tt.q, n, maxQuestionChars)
+			}
+		})
+	}
+
+	maxQuestionChars = 0
+	truncateQuestion = false
+	if got, err := validateQuestion(long); err != nil || got != long {
+		t.Errorf("with MAX_QUESTION_CHARS=0: %q, %v; want the question unchanged", got, err)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// feedRateLimiter paces every feed request, retries included (FEED_RATE_LIMIT, in requests per second).
+// It lets every request through until setFeedRate sets a limit.
+var feedRateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+// setFeedRate limits feed requests to perSecond per second, one at a time; zero or less removes the limit.
+func setFeedRate(perSecond float64) {
+	limit := rate.Limit(perSecond)
+	if perSecond <= 0 || math.IsInf(perSecond, 1) {
+		limit = rate.Inf
+	}
+	feedRateLimiter.SetLimit(limit)
+}
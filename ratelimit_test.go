@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestFeedRateLimitSpacesRequests(t *testing.T) {
+	setFeedRate(20) // one request every 50ms
+	t.Cleanup(func() { setFeedRate(0) })
+
+	var mu sync.Mutex
+	var arrivals []time.Time
+	srv, _ := countingFeedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		first := len(arrivals) == 1
+		mu.Unlock()
+		if first {
+			// The retry of a failed attempt waits for its turn too.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testFeed))
+	})
+
+	for i := range 3 {
+		if _, err := fetchRSSItems(context.Background(), srv.URL+"/rss", nil, 10); err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(arrivals) != 4 {
+		t.Fatalf("server saw %d requests, want 4 (one retry and three successes)", len(arrivals))
+	}
+	const minGap = 40 * time.Millisecond // the limiter allows some slack on timer precision
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < minGap {
+			t.Errorf("request %d came %v after the previous one, want at least %v", i, gap, minGap)
+		}
+	}
+}
+
+func TestSetFeedRateRemovesLimit(t *testing.T) {
+	setFeedRate(1)
+	setFeedRate(0)
+	if lim := feedRateLimiter.Limit(); lim != rate.Inf {
+		t.Errorf("after setFeedRate(0) the limit is %v, want none", lim)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// redactedParams lists the query parameter names (lowercase) whose values are masked in logs (REDACT_URL_PARAMS).
+var redactedParams = []string{"token", "access_token", "key", "api_key", "apikey", "secret", "signature", "sig", "password"}
+
+const redactedValue = "REDACTED"
+
+// redactURL masks the values of sensitive query parameters in raw, keeping parameter order.
+// Values that do not parse as URLs are returned unchanged.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	parts := strings.Split(u.RawQuery, "&")
+	for i, p := range parts {
+		name, _, hasValue := strings.Cut(p, "=")
+		if !hasValue {
+			continue
+		}
+		if key, err := url.QueryUnescape(name); err == nil && isRedactedParam(key) {
+			parts[i] = name + "=" + redactedValue
+		}
+	}
+	u.RawQuery = strings.Join(parts, "&")
+	return u.String()
+}
+
+// redactURLs applies redactURL to each element of urls.
+func redactURLs(urls []string) []string {
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = redactURL(u)
+	}
+	return out
+}
+
+// redactURLError masks the URL carried by a *url.Error, which the HTTP client includes in its messages.
+func redactURLError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = redactURL(ue.URL)
+	}
+	return err
+}
+
+func isRedactedParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range redactedParams {
+		if name == p {
+			return true
+		}
+	}
+	return false
+}
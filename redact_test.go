@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	saved := redactedParams
+	t.Cleanup(func() { redactedParams = saved })
+
+	tests := []struct{ in, want string }{
+		{"https://example.com/rss?token=s3cret&page=2", "https://example.com/rss?token=REDACTED&page=2"},
+		{"https://example.com/rss?API_KEY=s3cret", "https://example.com/rss?API_KEY=REDACTED"},
+		{"https://example.com/rss?page=2&flag", "https://example.com/rss?page=2&flag"},
+		{"https://example.com/rss", "https://example.com/rss"},
+	}
+	for _, tt := range tests {
+		if got := redactURL(tt.in); got != tt.want {
+			t.Errorf("redactURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	redactedParams = []string{"feedauth"}
+	if got := redactURL("https://example.com/rss?feedauth=s3cret&token=visible"); got != "https://example.com/rss?feedauth=REDACTED&token=visible" {
+		t.Errorf("with REDACT_URL_PARAMS=feedauth: %q", got)
+	}
+}
+
+func TestFeedLogsRedactTokens(t *testing.T) {
+	srv := serveFeed(t, testFeed)
+	srv.Close()
+	feedURL := srv.URL + "/rss?token=s3cret"
+	attempts := captureLogs(t, "feed attempt failed")
+
+	_, _, _, _, err := fetchFirstWorkingFeed(context.Background(), []string{feedURL}, nil, 5, true)
+	if err == nil {
+		t.Fatal("fetching a closed server: want an error")
+	}
+	records := attempts()
+	if len(records) != 1 {
+		t.Fatalf("logged %d failed attempts, want 1", len(records))
+	}
+	if logged := fmt.Sprint(records[0]); strings.Contains(logged, "s3cret") || !strings.Contains(logged, "token="+redactedValue) {
+		t.Errorf("log record leaks the token or lacks the redacted URL: %s", logged)
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("returned error leaks the token: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Embedding re-rank (-rerank): candidate items are ordered by the cosine similarity of their title
+// to the question instead of being selected by transfer keywords.
+var (
+	rerankEmbedder     ai.Embedder                     // nil keeps keyword filtering
+	rerankEmbedderName = "googleai/text-embedding-004" // RERANK_EMBEDDER
+	rerankTopN         = 8                             // RERANK_TOP_N
+)
+
+// lookupEmbedder resolves a "provider/name" embedder reference, returning nil when it is not registered.
+func lookupEmbedder(g *genkit.Genkit, ref string) ai.Embedder {
+	provider, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil
+	}
+	return genkit.LookupEmbedder(g, provider, name)
+}
+
+// rerankItems embeds question and the title of each item in a single call and returns the topN items
+// most similar to the question, most similar first. Ties keep the input order.
+func rerankItems(ctx context.Context, e ai.Embedder, question string, items []rssItem, topN int) ([]rssItem, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+	docs := make([]*ai.Document, 0, len(items)+1)
+	docs = append(docs, ai.DocumentFromText(question, nil))
+	for _, it := range items {
+		docs = append(docs, ai.DocumentFromText(it.Title, nil))
+	}
+	resp, err := ai.Embed(ctx, e, ai.WithDocs(docs...))
+	if err != nil {
+		return nil, fmt.Errorf("embedding items: %w", err)
+	}
+	if len(resp.Embeddings) != len(docs) {
+		return nil, fmt.Errorf("embedding items: got %d embeddings for %d documents", len(resp.Embeddings), len(docs))
+	}
+
+	query := resp.Embeddings[0].Embedding
+	scores := make([]float64, len(items))
+	order := make([]int, len(items))
+	for i := range items {
+		scores[i] = cosineSimilarity(query, resp.Embeddings[i+1].Embedding)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	if topN > 0 && len(order) > topN {
+		order = order[:topN]
+	}
+
+	out := make([]rssItem, len(order))
+	for i, idx := range order {
+		out[i] = items[idx]
+	}
+	return out, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when either is empty,
+// zero or their lengths differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// rerankOrFallback re-ranks candidates against question when an embedder is configured, and
+// returns keywordItems (the keyword-filtered selection) otherwise or when embedding fails.
+func rerankOrFallback(ctx context.Context, question string, candidates, keywordItems []rssItem) []rssItem {
+	if rerankEmbedder == nil || strings.TrimSpace(question) == "" {
+		return keywordItems
+	}
+	ranked, err := rerankItems(ctx, rerankEmbedder, question, candidates, rerankTopN)
+	if err != nil {
+		slog.Warn("re-rank failed, keeping keyword filtering", "embedder", rerankEmbedder.Name(), "err", err)
+		return keywordItems
+	}
+	slog.Debug("items re-ranked", "embedder", rerankEmbedder.Name(), "candidates", len(candidates), "kept", len(ranked))
+	return ranked
+}
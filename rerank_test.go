@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defineWordEmbedder registers an embedder whose vectors count the occurrences of "transfert" and
+// "course" in each document, so similarity to the question is predictable.
+func defineWordEmbedder(t *testing.T, g *genkit.Genkit, name string, err error) ai.Embedder {
+	t.Helper()
+	return genkit.DefineEmbedder(g, "test", name, func(_ context.Context, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+		if err != nil {
+			return nil, err
+		}
+		resp := &ai.EmbedResponse{}
+		for _, doc := range req.Input {
+			text := strings.ToLower(doc.Content[0].Text)
+			resp.Embeddings = append(resp.Embeddings, &ai.Embedding{Embedding: []float32{
+				float32(strings.Count(text, "transfert")),
+				float32(strings.Count(text, "course")),
+			}})
+		}
+		return resp, nil
+	})
+}
+
+func titles(items []rssItem) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.Title
+	}
+	return out
+}
+
+func TestRerankItems(t *testing.T) {
+	g := newTestGenkit(t)
+	e := defineWordEmbedder(t, g, "words", nil)
+	items := []rssItem{
+		{Title: "Résultats de la course"},
+		{Title: "Transfert et course : Pogačar"},
+		{Title: "Transfert : Evenepoel rejoint Ineos"},
+	}
+
+	got, err := rerankItems(context.Background(), e, "Quel transfert ?", items, 0)
+	if err != nil {
+		t.Fatalf("rerankItems: %v", err)
+	}
+	want := []string{"Transfert : Evenepoel rejoint Ineos", "Transfert et course : Pogačar", "Résultats de la course"}
+	if !slices.Equal(titles(got), want) {
+		t.Errorf("order = %q, want %q", titles(got), want)
+	}
+
+	got, err = rerankItems(context.Background(), e, "Quel transfert ?", items, 1)
+	if err != nil || !slices.Equal(titles(got), want[:1]) {
+		t.Errorf("topN 1 = %q, %v, want %q", titles(got), err, want[:1])
+	}
+}
+
+func TestRerankOrFallback(t *testing.T) {
+	g := newTestGenkit(t)
+	saved := rerankEmbedder
+	t.Cleanup(func() { rerankEmbedder = saved })
+
+	candidates := []rssItem{{Title: "Résultats de la course"}, {Title: "Transfert : Evenepoel rejoint Ineos"}}
+	keywordItems := candidates[1:]
+
+	if e := lookupEmbedder(g, "test/missing"); e != nil {
+		t.Fatalf("lookupEmbedder(unregistered) = %v, want nil", e)
+	}
+	rerankEmbedder = nil
+	if got := rerankOrFallback(context.Background(), "Quel transfert ?", candidates, keywordItems); !slices.Equal(titles(got), titles(keywordItems)) {
+		t.Errorf("without an embedder = %q, want the keyword selection %q", titles(got), titles(keywordItems))
+	}
+
+	rerankEmbedder = defineWordEmbedder(t, g, "failing", errors.New("quota exceeded"))
+	if got := rerankOrFallback(context.Background(), "Quel transfert ?", candidates, keywordItems); !slices.Equal(titles(got), titles(keywordItems)) {
+		t.Errorf("with a failing embedder = %q, want the keyword selection %q", titles(got), titles(keywordItems))
+	}
+
+	defineWordEmbedder(t, g, "words", nil)
+	if rerankEmbedder = lookupEmbedder(g, "test/words"); rerankEmbedder == nil {
+		t.Fatal("lookupEmbedder(test/words) = nil")
+	}
+	if got := rerankOrFallback(context.Background(), "Quel transfert ?", candidates, keywordItems); len(got) != 2 || got[0].Title != candidates[1].Title {
+		t.Errorf("re-ranked = %q, want every candidate with the transfer first", titles(got))
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Retry policy for feed requests (FEED_MAX_ATTEMPTS). Delays double from feedRetryBaseDelay, with jitter.
+var (
+	feedMaxAttempts    = 3
+	feedRetryBaseDelay = 500 * time.Millisecond
+)
+
+// statusError reports a non-2xx feed response.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string { return fmt.Sprintf("status %d", e.code) }
+
+// isTransientFetchError reports whether a failed feed request is worth retrying: timeouts,
+// connection resets, 429 and 5xx are; anything else, cancellation included, is not. A timeout of
+// the caller's own context is not told apart here: doWithRetry checks the request context for that.
+func isTransientFetchError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= 500
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET)
+}
+
+// doWithRetry sends req with feedClient, retrying transient failures up to feedMaxAttempts times.
+// Each attempt first waits for its turn on feedRateLimiter. The caller owns the body of the returned response, which always has a 2xx or 304 status.
+func doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	var lastErr error
+	for attempt := 1; attempt <= feedMaxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleepCtx(ctx, backoffDelay(feedRetryBaseDelay, attempt-1)); err != nil {
+				return nil, lastErr
+			}
+			slog.Debug("feed retry", "attempt", attempt, "max_attempts", feedMaxAttempts, "url", redactURL(req.URL.String()), "err", lastErr)
+		}
+
+		if err := feedRateLimiter.Wait(ctx); err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			return nil, lastErr
+		}
+		resp, err := feedClient.Do(req)
+		// 304 is only returned to conditional requests; fetchRSSItems answers it from its stored items.
+		if err == nil && resp.StatusCode != http.StatusNotModified && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			resp.Body.Close()
+			err = &statusError{code: resp.StatusCode}
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = redactURLError(err)
+		// Once the caller's context is done no attempt can succeed, whereas feedClient.Timeout
+		// only ends this one.
+		if req.Context().Err() != nil || !isTransientFetchError(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// backoffDelay returns the wait before retry number n (1-based): base·2^(n-1), jittered within [50%, 100%].
+func backoffDelay(base time.Duration, n int) time.Duration {
+	d := base << (n - 1)
+	return d/2 + rand.N(d/2+1)
+}
+
+// sleepCtx waits for d or until ctx is done, returning ctx.Err() in the latter case.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// slowServer starts a server that answers only after a second, counting the requests it receives.
+func slowServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	hits := new(atomic.Int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hits
+}
+
+// withFeedTimeout sets feedClient.Timeout to d for the duration of the test.
+func withFeedTimeout(t *testing.T, d time.Duration) {
+	saved := feedClient.Timeout
+	feedClient.Timeout = d
+	t.Cleanup(func() { feedClient.Timeout = saved })
+}
+
+func TestIsTransientFetchError(t *testing.T) {
+	srv, _ := slowServer(t)
+	withFeedTimeout(t, 20*time.Millisecond)
+	_, clientTimeout := feedClient.Get(srv.URL)
+	if clientTimeout == nil {
+		t.Fatal("the slow server answered within feedClient.Timeout")
+	}
+
+	opErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://example.com/rss", Err: &net.OpError{Op: "read", Net: "tcp", Err: err}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"client timeout", clientTimeout, true},
+		{"connection reset", opErr(os.NewSyscallError("read", syscall.ECONNRESET)), true},
+		{"status 503", fmt.Errorf("fetching: %w", &statusError{code: 503}), true},
+		{"status 429", &statusError{code: 429}, true},
+		{"status 404", &statusError{code: 404}, false},
+		{"connection refused", opErr(os.NewSyscallError("connect", syscall.ECONNREFUSED)), false},
+		{"unknown host", opErr(&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}), false},
+		{"host policy", errors.New("feed host not allowed"), false},
+		{"canceled", opErr(context.Canceled), false},
+	}
+	for _, tt := range tests {
+		if got := isTransientFetchError(tt.err); got != tt.want {
+			t.Errorf("%s: isTransientFetchError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDoWithRetryRetriesTimeoutsButNotTheCallerDeadline(t *testing.T) {
+	srv, hits := slowServer(t)
+	savedDelay := feedRetryBaseDelay
+	feedRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { feedRetryBaseDelay = savedDelay })
+
+	withFeedTimeout(t, 20*time.Millisecond)
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if _, err := doWithRetry(context.Background(), req); err == nil {
+		t.Fatal("doWithRetry succeeded against the slow server")
+	}
+	if n := hits.Load(); n != int32(feedMaxAttempts) {
+		t.Errorf("feedClient.Timeout: %d attempts, want %d", n, feedMaxAttempts)
+	}
+
+	hits.Store(0)
+	withFeedTimeout(t, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := doWithRetry(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("caller deadline: got %v, want context.DeadlineExceeded", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("caller deadline: %d attempts, want 1", n)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// shutdownGrace is how long in-flight requests may run once a shutdown is requested (SHUTDOWN_GRACE).
+var shutdownGrace = 10 * time.Second
+
+// serveFlows exposes every registered flow as POST /<flowName> on port, along with GET /healthz,
+// GET /readyz, GET /feeds and, with -metrics, GET /metrics. It blocks until the server fails or ctx is done;
+// then new connections are refused and in-flight requests get shutdownGrace to finish before their
+// contexts, and the feed fetches running under them, are cancelled.
+func serveFlows(ctx context.Context, g *genkit.Genkit, port int) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /readyz", handleReadyz)
+	mux.HandleFunc("GET /feeds", handleFeeds)
+	if metricsEnabled {
+		mux.Handle("GET /metrics", metrics)
+	}
+	go warmFeeds(ctx)
+
+	for _, f := range genkit.ListFlows(g) {
+		mux.HandleFunc("POST /"+f.Name(), acceptBareInput(genkit.Handler(f)))
+		slog.Info("flow servi", "flow", f.Name(), "route", "POST /"+f.Name())
+	}
+
+	// Requests outlive ctx so that they can finish during the grace period.
+	reqCtx, cancelRequests := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRequests()
+	addr := fmt.Sprintf(":%d", port)
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return reqCtx },
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	slog.Info("serveur HTTP à l'écoute", "addr", addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	slog.Info("arrêt du serveur HTTP", "grace", shutdownGrace)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		cancelRequests()
+		slog.Warn("délai d'arrêt dépassé, requêtes en cours interrompues", "grace", shutdownGrace)
+		return errors.Join(err, srv.Close())
+	}
+	return nil
+}
+
+// Request body caps of acceptBareInput: a question may take up to 6 bytes per character once
+// JSON-escaped, and envelopeBytes leaves room for the other fields (extraContext among them).
+const (
+	envelopeBytes       = 64 << 10
+	uncappedBodyBytes   = 1 << 20 // used when MAX_QUESTION_CHARS is 0
+	bytesPerEscapedChar = 6
+)
+
+// maxRequestBytes is the largest flow request body acceptBareInput reads.
+func maxRequestBytes() int64 {
+	if maxQuestionChars <= 0 {
+		return uncappedBodyBytes
+	}
+	return int64(maxQuestionChars)*bytesPerEscapedChar + envelopeBytes
+}
+
+// acceptBareInput lets callers POST the flow input directly (e.g. {"question":"..."}) in addition to
+// genkit's {"data": ...} envelope, by wrapping bodies that lack a "data" field before calling next.
+// Bodies larger than maxRequestBytes are refused with 413.
+func acceptBareInput(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes()))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		r.Body.Close()
+
+		// Bodies that are not JSON objects are passed through for genkit to reject.
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(body, &envelope); err == nil && envelope["data"] == nil {
+			if wrapped, err := json.Marshal(map[string]json.RawMessage{"data": body}); err == nil {
+				body = wrapped
+			}
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next(w, r)
+	}
+}
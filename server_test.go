@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestAcceptBareInput(t *testing.T) {
+	saved := maxQuestionChars
+	maxQuestionChars = 100
+	t.Cleanup(func() { maxQuestionChars = saved })
+
+	var got string
+	handler := acceptBareInput(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("POST", "/qaFlow", strings.NewReader(`{"question":"Qui ?"}`)))
+	if rec.Code != http.StatusOK || got != `{"data":{"question":"Qui ?"}}` {
+		t.Errorf("bare input: status %d, next got %q; want it wrapped in a data envelope", rec.Code, got)
+	}
+
+	got = ""
+	rec = httptest.NewRecorder()
+	huge := `{"question":"` + strings.Repeat("a", int(maxRequestBytes())) + `"}`
+	handler(rec, httptest.NewRequest("POST", "/qaFlow", strings.NewReader(huge)))
+	if rec.Code != http.StatusRequestEntityTooLarge || got != "" {
+		t.Errorf("oversized body: status %d, next called = %v; want 413 without calling next", rec.Code, got != "")
+	}
+}
+
+// freePort returns a TCP port that was free a moment ago, for serveFlows which takes a port number.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestServeFlowsDrainsInFlightRequests(t *testing.T) {
+	serveFeed(t, testFeed)
+	savedGrace := shutdownGrace
+	t.Cleanup(func() { shutdownGrace = savedGrace })
+
+	tests := []struct {
+		name      string
+		grace     time.Duration
+		wantFlow  string // what the in-flight flow ended with
+		wantReply string // part of the reply it got
+		wantErr   bool
+	}{
+		{"finishes within the grace period", 5 * time.Second, "done", `"done"`, false},
+		{"cancelled once the grace period is over", 50 * time.Millisecond, "context canceled", "EOF", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdownGrace = tt.grace
+			g := newTestGenkit(t)
+			started, release, ended := make(chan struct{}), make(chan struct{}), make(chan string, 1)
+			genkit.DefineFlow(g, "slowFlow", func(ctx context.Context, _ string) (string, error) {
+				close(started)
+				select {
+				case <-release:
+					ended <- "done"
+					return "done", nil
+				case <-ctx.Done():
+					ended <- ctx.Err().Error()
+					return "", ctx.Err()
+				}
+			})
+
+			port := freePort(t)
+			base := fmt.Sprintf("http://127.0.0.1:%d", port)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			served := make(chan error, 1)
+			go func() { served <- serveFlows(ctx, g, port) }()
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+				if resp, err := http.Get(base + "/healthz"); err == nil {
+					resp.Body.Close()
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("server did not start")
+				}
+			}
+
+			replied := make(chan string, 1)
+			go func() {
+				resp, err := http.Post(base+"/slowFlow", "application/json", strings.NewReader(`{"data":"q"}`))
+				if err != nil {
+					replied <- err.Error()
+					return
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				replied <- string(body)
+			}()
+			<-started
+			cancel()
+
+			if tt.grace > time.Second {
+				// Shutdown closes the listener first: new requests are refused while the slow one runs.
+				for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+					resp, err := http.Get(base + "/healthz")
+					if err != nil {
+						break
+					}
+					resp.Body.Close()
+					if time.Now().After(deadline) {
+						t.Fatal("server still accepts requests after shutdown started")
+					}
+				}
+				release <- struct{}{}
+			}
+			if got := <-ended; got != tt.wantFlow {
+				t.Errorf("in-flight flow ended with %q, want %q", got, tt.wantFlow)
+			}
+			if got := <-replied; !strings.Contains(got, tt.wantReply) {
+				t.Errorf("in-flight reply = %q, want it to contain %q", got, tt.wantReply)
+			}
+			if err := <-served; (err != nil) != tt.wantErr {
+				t.Errorf("serveFlows = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
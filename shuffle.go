@@ -0,0 +1,21 @@
+package main
+
+import "math/rand/v2"
+
+// Feed order randomization (FEED_SHUFFLE, FEED_SHUFFLE_SEED). A zero seed shuffles differently on every request.
+var (
+	feedShuffle     = false
+	feedShuffleSeed int64
+)
+
+// shuffleFeeds returns a shuffled copy of feeds. A non-zero seed always yields the same order.
+func shuffleFeeds(feeds []feedConfig, seed int64) []feedConfig {
+	shuffled := append([]feedConfig(nil), feeds...)
+	swap := func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] }
+	if seed == 0 {
+		rand.Shuffle(len(shuffled), swap)
+		return shuffled
+	}
+	rand.New(rand.NewPCG(uint64(seed), 0)).Shuffle(len(shuffled), swap)
+	return shuffled
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestShuffleFeedsSeed(t *testing.T) {
+	var feeds []feedConfig
+	for i := range 10 {
+		feeds = append(feeds, feedConfig{name: fmt.Sprintf("feed%d", i)})
+	}
+	names := func(fs []feedConfig) []string {
+		var out []string
+		for _, f := range fs {
+			out = append(out, f.name)
+		}
+		return out
+	}
+	original := names(feeds)
+
+	first := names(shuffleFeeds(feeds, 42))
+	if again := names(shuffleFeeds(feeds, 42)); !slices.Equal(first, again) {
+		t.Errorf("seed 42 gave %v then %v, want the same order", first, again)
+	}
+	if other := names(shuffleFeeds(feeds, 7)); slices.Equal(first, other) {
+		t.Errorf("seeds 42 and 7 gave the same order %v", first)
+	}
+	if slices.Equal(first, original) {
+		t.Errorf("seed 42 left the order unchanged")
+	}
+	sorted := slices.Clone(first)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, original) {
+		t.Errorf("shuffled feeds %v are not a permutation of %v", first, original)
+	}
+	if !slices.Equal(names(feeds), original) {
+		t.Error("shuffleFeeds modified its input")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// stripTrackingParams removes tracking query parameters from source URLs (STRIP_TRACKING_PARAMS).
+var stripTrackingParams = true
+
+// trackingParams are the query parameter names (lowercase) dropped from sources; utm_* is matched by prefix.
+var trackingParams = []string{"fbclid", "gclid", "xtor", "at_medium", "at_campaign"}
+
+// sanitizeSourceURL returns raw as a source URL, or false when it should be left out: it must parse,
+// be absolute (relative links are resolved by fetchRSSItems) and use http or https. Tracking
+// parameters are removed when stripTrackingParams is set, keeping the order of the others.
+func sanitizeSourceURL(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	default:
+		return "", false
+	}
+	if !stripTrackingParams || u.RawQuery == "" {
+		return u.String(), true
+	}
+
+	var kept []string
+	for _, p := range strings.Split(u.RawQuery, "&") {
+		name, _, _ := strings.Cut(p, "=")
+		if key, err := url.QueryUnescape(name); err == nil && isTrackingParam(key) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	return u.String(), true
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "utm_") {
+		return true
+	}
+	for _, p := range trackingParams {
+		if name == p {
+			return true
+		}
+	}
+	return false
+}
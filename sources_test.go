@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestSanitizeSourceURL(t *testing.T) {
+	saved := stripTrackingParams
+	t.Cleanup(func() { stripTrackingParams = saved })
+
+	tests := []struct {
+		raw    string
+		strip  bool
+		want   string
+		wantOK bool
+	}{
+		{" https://example.com/a ", true, "https://example.com/a", true},
+		{"https://example.com/a?utm_source=x&id=3&UTM_Medium=y&fbclid=z", true, "https://example.com/a?id=3", true},
+		{"https://example.com/a?utm_source=x&id=3", false, "https://example.com/a?utm_source=x&id=3", true},
+		{"HTTP://example.com/a?xtor=RSS-1", true, "http://example.com/a", true},
+		{"/relative/path", true, "", false},
+		{"javascript:alert(1)", true, "", false},
+		{"ftp://example.com/file", true, "", false},
+		{"https://exa mple.com/%zz", true, "", false},
+	}
+	for _, tt := range tests {
+		stripTrackingParams = tt.strip
+		got, ok := sanitizeSourceURL(tt.raw)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("sanitizeSourceURL(%q) strip=%v = %q, %v; want %q, %v", tt.raw, tt.strip, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+// flowSummary is the compact, one-line record logged once per flow run.
+type flowSummary struct {
+	Flow       string
+	Question   string
+	FeedsOK    int
+	FeedsTotal int
+	Items      int
+	Model      string
+	// CacheHit is set when the run was answered from a cache: the qaFlow answer cache, or
+	// itemCache for every feed that answered.
+	CacheHit bool
+	Duration time.Duration
+	Err      error
+}
+
+// logFlowSummary emits s as a single "flowSummary" record with one attribute per field.
+func logFlowSummary(s flowSummary) {
+	status := "ok"
+	if s.Err != nil {
+		status = "error"
+	}
+	slog.Info("flowSummary",
+		"flow", s.Flow, "status", status, "question", s.Question,
+		"feeds_ok", s.FeedsOK, "feeds_total", s.FeedsTotal, "items", s.Items,
+		"model", s.Model, "cache_hit", s.CacheHit, "duration", s.Duration.Round(time.Millisecond))
+}
+
+// respondingModel is the model reported by the summary of a qaFlow run: the one that answered, or
+// the configured one when the run failed before any answer.
+func respondingModel(answered string) string {
+	if answered != "" {
+		return answered
+	}
+	return modelName
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureLogs routes slog to a buffer for the duration of the test and returns a function
+// decoding the records with message msg logged so far.
+func captureLogs(t *testing.T, msg string) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	saved := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(saved) })
+	return func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var r map[string]any
+			if json.Unmarshal([]byte(line), &r) == nil && r["msg"] == msg {
+				records = append(records, r)
+			}
+		}
+		return records
+	}
+}
+
+func TestQAFlowSummaryReportsModelAndCacheHit(t *testing.T) {
+	saved := qaAnswers
+	qaAnswers = newAnswerCache(4, time.Minute)
+	t.Cleanup(func() { qaAnswers = saved })
+	summaries := captureLogs(t, "flowSummary")
+
+	flow := defineQAFlow(newTestGenkit(t), &stubGenerator{reply: replyText("Pogačar.", "stub/fallback")})
+	for range 2 {
+		if _, err := flow.Run(context.Background(), QuestionInput{Question: "Qui a gagné le Tour ?"}); err != nil {
+			t.Fatalf("qaFlow: %v", err)
+		}
+	}
+
+	records := summaries()
+	if len(records) != 2 {
+		t.Fatalf("got %d flowSummary records, want 2", len(records))
+	}
+	for i, wantHit := range []bool{false, true} {
+		if records[i]["model"] != "stub/fallback" || records[i]["cache_hit"] != wantHit {
+			t.Errorf("run %d: model %v, cache_hit %v; want stub/fallback, %v", i+1, records[i]["model"], records[i]["cache_hit"], wantHit)
+		}
+	}
+}
+
+func TestCyclingRAGSummaryReportsFeedCacheHit(t *testing.T) {
+	serveFeed(t, testFeed)
+	summaries := captureLogs(t, "flowSummary")
+	flow := defineCyclingRAGFlow(newTestGenkit(t), &stubGenerator{reply: replyText(`{"answer":"ok"}`, "stub")})
+
+	for _, refresh := range []bool{true, false} {
+		if _, err := flow.Run(context.Background(), CyclingRAGInput{Refresh: refresh}); err != nil {
+			t.Fatalf("cyclingRAG: %v", err)
+		}
+	}
+	records := summaries()
+	if len(records) != 2 || records[0]["cache_hit"] != false || records[1]["cache_hit"] != true {
+		t.Errorf("flowSummary records = %v, want a miss then an item cache hit", records)
+	}
+}
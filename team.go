@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// teamRoster is the structured answer requested from the model when CyclingRAGInput.TeamFocus is set.
+type teamRoster struct {
+	Summary    string   `json:"summary"`
+	Arrivals   []string `json:"arrivals"`
+	Departures []string `json:"departures"`
+}
+
+// foldAccents lowercases s and strips its diacritics, so "Pogačar" and "pogacar" compare equal.
+func foldAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// teamAliases maps the short, sponsor or former names headlines use to the canonical team name.
+var teamAliases = map[string]string{
+	"UAE":            "UAE Team Emirates",
+	"UAE Emirates":   "UAE Team Emirates",
+	"Visma":          "Visma-Lease a Bike",
+	"Jumbo-Visma":    "Visma-Lease a Bike",
+	"Ineos":          "INEOS Grenadiers",
+	"Soudal":         "Soudal Quick-Step",
+	"Quick-Step":     "Soudal Quick-Step",
+	"Bora":           "Red Bull-BORA-hansgrohe",
+	"BORA-hansgrohe": "Red Bull-BORA-hansgrohe",
+	"Red Bull":       "Red Bull-BORA-hansgrohe",
+	"FDJ":            "Groupama-FDJ",
+	"AG2R":           "Decathlon AG2R La Mondiale",
+	"Decathlon":      "Decathlon AG2R La Mondiale",
+	"Arkéa":          "Arkéa-B&B Hotels",
+	"EF":             "EF Education-EasyPost",
+	"Alpecin":        "Alpecin-Deceuninck",
+	"Jayco":          "Team Jayco AlUla",
+	"Intermarché":    "Intermarché-Wanty",
+}
+
+// canonicalTeam returns the canonical name of team when it is a teamAliases entry, ignoring case
+// and accents, and team itself otherwise.
+func canonicalTeam(team string) string {
+	team = strings.TrimSpace(team)
+	folded := foldAccents(team)
+	for alias, canonical := range teamAliases {
+		if foldAccents(alias) == folded {
+			return canonical
+		}
+	}
+	return team
+}
+
+// teamNames returns the folded names that designate team: its canonical name and every alias of it.
+func teamNames(team string) []string {
+	canonical := canonicalTeam(team)
+	names := []string{foldAccents(canonical)}
+	for alias, c := range teamAliases {
+		if foldAccents(c) == names[0] {
+			names = append(names, foldAccents(alias))
+		}
+	}
+	return names
+}
+
+// mentionsTeam reports whether folded, a title passed through foldAccents, names team or one of
+// its aliases as whole words.
+func mentionsTeam(folded, team string) bool {
+	for _, name := range teamNames(team) {
+		if containsWord(folded, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTeamItems keeps the items whose title mentions team or one of its aliases, ignoring case
+// and accents. An empty team keeps everything.
+func filterTeamItems(items []rssItem, team string) []rssItem {
+	if strings.TrimSpace(team) == "" {
+		return items
+	}
+	var filtered []rssItem
+	for _, it := range items {
+		if mentionsTeam(foldAccents(it.Title), team) {
+			filtered = append(filtered, it)
+		}
+	}
+	return filtered
+}
+
+// generateTeamRoster asks the model for every arrival and departure of team found in contextBlock.
+// When the model's JSON is malformed, its raw text is returned as the summary with empty lists.
+func generateTeamRoster(ctx context.Context, gen Generator, contextBlock, team string) (*teamRoster, GenerateResult, error) {
+	prompt := fmt.Sprintf(
+		"Tu es un assistant cyclisme.\n"+
+			"Contexte issu de flux d'actualités (mutations/transferts) :\n%s\n\n"+
+			"Liste tous les mouvements d'effectif de l'équipe %s présents dans le contexte : "+
+			"les arrivées (coureur et équipe d'origine) et les départs (coureur et équipe de destination), "+
+			"en précisant s'il s'agit d'une rumeur. Ajoute un court résumé en français. N'invente aucun mouvement.",
+		contextBlock, team,
+	)
+	roster, res, err := generateData[teamRoster](ctx, gen, GenerateRequest{Model: modelName, Prompt: prompt})
+	if errors.Is(err, errMalformedOutput) {
+		slog.Warn("sortie JSON du modèle invalide, arrivées et départs ignorés", "team", team, "err", err)
+		return &teamRoster{Summary: res.Text}, res, nil
+	}
+	return roster, res, err
+}
+
+// knownTeams are the team names looked for in item titles (-teams); nil disables the annotation.
+var knownTeams []string
+
+// teamsInTitle returns the names of teams that appear in title as whole words, directly or through
+// an alias, ignoring case and accents, in the order of teams.
+func teamsInTitle(title string, teams []string) []string {
+	folded := foldAccents(title)
+	var found []string
+	for _, team := range teams {
+		if mentionsTeam(folded, team) {
+			found = append(found, team)
+		}
+	}
+	return found
+}
+
+// containsWord reports whether word occurs in s without a letter or digit on either side.
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for start := 0; ; {
+		i := strings.Index(s[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+len(word):])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// annotateTeams sets the Teams of each item to the known teams its title mentions.
+func annotateTeams(items []rssItem, teams []string) []rssItem {
+	if len(teams) == 0 {
+		return items
+	}
+	out := make([]rssItem, len(items))
+	for i, it := range items {
+		it.Teams = teamsInTitle(it.Title, teams)
+		out[i] = it
+	}
+	return out
+}
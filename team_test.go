@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalTeam(t *testing.T) {
+	tests := map[string]string{
+		"UAE":         "UAE Team Emirates",
+		" uae ":       "UAE Team Emirates",
+		"arkea":       "Arkéa-B&B Hotels",
+		"Jumbo-Visma": "Visma-Lease a Bike",
+		"Cofidis":     "Cofidis",
+	}
+	for in, want := range tests {
+		if got := canonicalTeam(in); got != want {
+			t.Errorf("canonicalTeam(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFilterTeamItemsMatchesAliases(t *testing.T) {
+	items := []rssItem{
+		{Title: "Ayuso quitte UAE"},
+		{Title: "UAE Team Emirates prolonge Pogačar"},
+		{Title: "Transfert chez Visma"},
+		{Title: "Effectif de Cofidis"}, // "EF" is only matched as a whole word
+	}
+	for _, focus := range []string{"UAE Team Emirates", "uae"} {
+		got := filterTeamItems(items, focus)
+		if !reflect.DeepEqual(got, items[:2]) {
+			t.Errorf("filterTeamItems(%q) = %+v, want the two UAE items", focus, got)
+		}
+	}
+	if got := filterTeamItems(items, "EF Education-EasyPost"); len(got) != 0 {
+		t.Errorf("filterTeamItems(EF) = %+v, want none", got)
+	}
+}
+
+func TestTeamsInTitleReportsAliasesUnderTheListedName(t *testing.T) {
+	got := teamsInTitle("Ineos recrute un grimpeur de l'équipe Arkéa", []string{"Arkéa-B&B Hotels", "INEOS Grenadiers", "Cofidis"})
+	if want := []string{"Arkéa-B&B Hotels", "INEOS Grenadiers"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("teamsInTitle = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateTeamRosterFallsBackOnMalformedModelJSON(t *testing.T) {
+	g := newTestGenkit(t)
+	const reply = "Arrivée : Ayuso (UAE)."
+	model, calls := defineTextModel(g, "roster", reply)
+	savedModel := modelName
+	modelName = model
+	t.Cleanup(func() { modelName = savedModel })
+
+	roster, res, err := generateTeamRoster(context.Background(), genkitGenerator{g: g}, "- Ayuso rejoint Lidl-Trek", "Lidl-Trek")
+	if err != nil {
+		t.Fatalf("generateTeamRoster: %v", err)
+	}
+	if roster.Summary != reply || roster.Arrivals != nil || roster.Departures != nil {
+		t.Errorf("roster = %+v, want the raw answer as summary and no lists", roster)
+	}
+	if res.Model != model || *calls != 1 {
+		t.Errorf("Model = %q after %d calls, want %q once", res.Model, *calls, model)
+	}
+}
+
+func TestAnnotateTeamsTagsContextLines(t *testing.T) {
+	teams := []string{"Cofidis", "UAE Team Emirates", "Visma-Lease a Bike"}
+	tests := []struct {
+		title     string
+		wantTeams []string
+		wantLine  string
+	}{
+		{"Pogačar renouvelle son contrat", nil, "- Pogačar renouvelle son contrat (date inconnue)"},
+		{"Alaphilippe prolonge avec Cofidis", []string{"Cofidis"}, "- Alaphilippe prolonge avec Cofidis (date inconnue) [équipes : Cofidis]"},
+		{"Ayuso quitte UAE pour Visma", []string{"UAE Team Emirates", "Visma-Lease a Bike"}, "- Ayuso quitte UAE pour Visma (date inconnue) [équipes : UAE Team Emirates, Visma-Lease a Bike]"},
+	}
+	for _, tt := range tests {
+		got := annotateTeams([]rssItem{{Title: tt.title}}, teams)
+		if !reflect.DeepEqual(got[0].Teams, tt.wantTeams) {
+			t.Errorf("annotateTeams(%q) Teams = %v, want %v", tt.title, got[0].Teams, tt.wantTeams)
+		}
+		if line := contextSnippet(got[0]); line != tt.wantLine {
+			t.Errorf("contextSnippet(%q) = %q, want %q", tt.title, line, tt.wantLine)
+		}
+	}
+
+	items := []rssItem{{Title: "Alaphilippe prolonge avec Cofidis"}}
+	if got := annotateTeams(items, nil); !reflect.DeepEqual(got, items) {
+		t.Errorf("annotateTeams without known teams = %+v, want the items unchanged", got)
+	}
+}
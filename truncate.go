@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// truncationMarker is appended to text shortened by truncateText (TRUNCATION_MARKER).
+var truncationMarker = "…"
+
+// maxTitleChars caps the length of titles in context snippets (SNIPPET_TITLE_MAX_CHARS); 0 means no cap.
+var maxTitleChars = 0
+
+// truncateText shortens s to at most max runes, marker included, cutting at the last word boundary
+// that fits. A single word longer than the limit is cut mid-word. s is returned unchanged when it fits.
+func truncateText(s string, max int, marker string) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	m := []rune(marker)
+	keep := max - len(m)
+	if keep <= 0 {
+		return string(r[:max])
+	}
+
+	cut := keep
+	// r[keep] is the first dropped rune; unless it is a space, back up to the last space that fits.
+	if !unicode.IsSpace(r[keep]) {
+		for i := keep - 1; i > 0; i-- {
+			if unicode.IsSpace(r[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(r[:cut]), func(c rune) bool {
+		return unicode.IsSpace(c) || unicode.IsPunct(c)
+	}) + marker
+}
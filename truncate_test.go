@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s      string
+		max    int
+		marker string
+		want   string
+	}{
+		{"Pogačar rejoint Cofidis", 0, "…", "Pogačar rejoint Cofidis"},
+		{"Pogačar rejoint Cofidis", 23, "…", "Pogačar rejoint Cofidis"},
+		{"Pogačar rejoint Cofidis", 20, "…", "Pogačar rejoint…"},
+		{"Pogačar rejoint Cofidis", 16, "…", "Pogačar rejoint…"},
+		{"Pogačar, rejoint Cofidis", 12, "…", "Pogačar…"},
+		{"Pogačar rejoint Cofidis", 20, " [...]", "Pogačar [...]"},
+		{"Anticonstitutionnellement", 10, "…", "Anticonst…"},
+		{"Pogačar rejoint Cofidis", 2, "[...]", "Po"},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.s, tt.max, tt.marker); got != tt.want {
+			t.Errorf("truncateText(%q, %d, %q) = %q, want %q", tt.s, tt.max, tt.marker, got, tt.want)
+		}
+	}
+}
+
+func TestContextSnippetTruncatesTitle(t *testing.T) {
+	savedMax, savedMarker := maxTitleChars, truncationMarker
+	maxTitleChars, truncationMarker = 20, "…"
+	t.Cleanup(func() { maxTitleChars, truncationMarker = savedMax, savedMarker })
+
+	got := contextSnippet(rssItem{Title: "Transfert : Pogačar rejoint Cofidis", PubDate: "2 juin"})
+	if want := "- Transfert : Pogačar… (2 juin)"; got != want {
+		t.Errorf("contextSnippet = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "github.com/firebase/genkit/go/ai"
+
+// Per-1k-token prices used for the cost estimate (MODEL_COST_PER_1K_INPUT_TOKENS and
+// MODEL_COST_PER_1K_OUTPUT_TOKENS), in whatever currency they are given; 0 disables the estimate.
+var (
+	costPer1KInputTokens  float64
+	costPer1KOutputTokens float64
+)
+
+// TokenUsage reports the tokens a flow run consumed and a rough cost estimate. Fields stay zero
+// when the model does not report its usage.
+type TokenUsage struct {
+	InputTokens   int     `json:"inputTokens"`
+	OutputTokens  int     `json:"outputTokens"`
+	EstimatedCost float64 `json:"estimatedCost"`
+}
+
+// usageOf reads the token counts of res and prices them; a result without usage data gives zero.
+func usageOf(res GenerateResult) TokenUsage {
+	return tokenUsage(res.Usage)
+}
+
+// tokenUsage converts the usage metadata of a genkit response into a TokenUsage.
+func tokenUsage(u *ai.GenerationUsage) TokenUsage {
+	if u == nil {
+		return TokenUsage{}
+	}
+	return TokenUsage{
+		InputTokens:   u.InputTokens,
+		OutputTokens:  u.OutputTokens,
+		EstimatedCost: estimateCost(u.InputTokens, u.OutputTokens),
+	}
+}
+
+// add sums two usages, for flows that call the model more than once.
+func (u TokenUsage) add(o TokenUsage) TokenUsage {
+	return TokenUsage{
+		InputTokens:   u.InputTokens + o.InputTokens,
+		OutputTokens:  u.OutputTokens + o.OutputTokens,
+		EstimatedCost: u.EstimatedCost + o.EstimatedCost,
+	}
+}
+
+func estimateCost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)/1000*costPer1KInputTokens + float64(outputTokens)/1000*costPer1KOutputTokens
+}
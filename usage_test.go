@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestRunFeedRAGReportsTokenUsage(t *testing.T) {
+	serveFeed(t, testFeed)
+	savedIn, savedOut := costPer1KInputTokens, costPer1KOutputTokens
+	costPer1KInputTokens, costPer1KOutputTokens = 0.1, 0.4
+	t.Cleanup(func() { costPer1KInputTokens, costPer1KOutputTokens = savedIn, savedOut })
+
+	gen := &stubGenerator{reply: func(req GenerateRequest) (GenerateResult, error) {
+		return GenerateResult{
+			Text:  `{"answer":"Pogačar rejoint Cofidis.","mutations":[]}`,
+			Model: req.Model,
+			Usage: &ai.GenerationUsage{InputTokens: 1500, OutputTokens: 200},
+		}, nil
+	}}
+	out, _, err := RunFeedRAG(context.Background(), gen, cyclingRAGConfig(), CyclingRAGInput{Refresh: true})
+	if err != nil {
+		t.Fatalf("RunFeedRAG: %v", err)
+	}
+	if out.InputTokens != 1500 || out.OutputTokens != 200 || math.Abs(out.EstimatedCost-0.23) > 1e-9 {
+		t.Errorf("usage = %+v, want 1500 in, 200 out, cost 0.23", out.TokenUsage)
+	}
+	data, _ := json.Marshal(out)
+	for _, key := range []string{`"inputTokens":1500`, `"outputTokens":200`, `"estimatedCost":0.23`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("JSON output lacks %s: %s", key, data)
+		}
+	}
+}
+
+func TestTokenUsage(t *testing.T) {
+	if got := usageOf(GenerateResult{Text: "sans usage"}); got != (TokenUsage{}) {
+		t.Errorf("usageOf without usage data = %+v, want zero", got)
+	}
+	sum := TokenUsage{InputTokens: 10, OutputTokens: 2, EstimatedCost: 0.5}.add(TokenUsage{InputTokens: 5, OutputTokens: 1, EstimatedCost: 0.25})
+	if sum != (TokenUsage{InputTokens: 15, OutputTokens: 3, EstimatedCost: 0.75}) {
+		t.Errorf("add = %+v", sum)
+	}
+}